@@ -0,0 +1,62 @@
+// Package accesslog 是各框架的访问日志中间件共用的格式解析、渲染与等级选择
+// 中间件只负责在请求结束后从各自框架的上下文中填充 Entry, 并将渲染出的一行日志写入 logger
+package accesslog
+
+import "time"
+
+// Entry 是渲染一行访问日志所需的数据
+type Entry struct {
+	ClientIP  string
+	Method    string
+	Protocol  string
+	Path      string
+	UserAgent string
+	Status    int
+	// Bytes 是响应体的字节数, 未知时为 0
+	Bytes int
+	// RequestID 是请求 ID, 未使用请求 ID 中间件时为空
+	RequestID string
+	Latency   time.Duration
+}
+
+// Level 是访问日志使用的 logger 等级
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// DefaultStatusLevel 是默认的状态码到日志等级的映射: 5xx 为 Error, 4xx 为 Warn, 其余为 Info
+func DefaultStatusLevel(status int) Level {
+	switch {
+	case status >= 500:
+		return LevelError
+	case status >= 400:
+		return LevelWarn
+	}
+	return LevelInfo
+}
+
+// PathSet 是完全匹配的请求路径集合, 用于实现中间件的 SkipPaths
+type PathSet map[string]struct{}
+
+// NewPathSet 返回包含 paths 的集合, paths 为空时返回 nil, nil 集合不包含任何路径
+func NewPathSet(paths []string) PathSet {
+	if len(paths) == 0 {
+		return nil
+	}
+	s := make(PathSet, len(paths))
+	for _, path := range paths {
+		s[path] = struct{}{}
+	}
+	return s
+}
+
+// Contains 判断 path 是否在集合中
+func (s PathSet) Contains(path string) bool {
+	_, ok := s[path]
+	return ok
+}
@@ -0,0 +1,27 @@
+package accesslog
+
+import "testing"
+
+func TestDefaultStatusLevel(t *testing.T) {
+	for status, want := range map[int]Level{
+		100: LevelInfo, 200: LevelInfo, 302: LevelInfo, 399: LevelInfo,
+		400: LevelWarn, 404: LevelWarn, 499: LevelWarn,
+		500: LevelError, 503: LevelError,
+	} {
+		if got := DefaultStatusLevel(status); got != want {
+			t.Errorf("DefaultStatusLevel(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestPathSet(t *testing.T) {
+	if s := NewPathSet(nil); s != nil || s.Contains("/") {
+		t.Errorf("NewPathSet(nil) = %v, want an empty nil set", s)
+	}
+	s := NewPathSet([]string{"/healthz", "/metrics"})
+	for path, want := range map[string]bool{"/healthz": true, "/metrics": true, "/healthz/": false, "/": false} {
+		if got := s.Contains(path); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultFormat 是中间件使用的默认日志格式
+const DefaultFormat = "{clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {latency} "
+
+// fields 是格式中可用的占位符及其渲染函数
+var fields = map[string]func(b []byte, e *Entry) []byte{
+	"clientIP": func(b []byte, e *Entry) []byte {
+		return append(b, e.ClientIP...)
+	},
+	"method": func(b []byte, e *Entry) []byte {
+		return append(b, e.Method...)
+	},
+	"protocol": func(b []byte, e *Entry) []byte {
+		return append(b, e.Protocol...)
+	},
+	"path": func(b []byte, e *Entry) []byte {
+		return append(b, e.Path...)
+	},
+	"userAgent": func(b []byte, e *Entry) []byte {
+		return append(b, e.UserAgent...)
+	},
+	"status": func(b []byte, e *Entry) []byte {
+		return strconv.AppendInt(b, int64(e.Status), 10)
+	},
+	"bytes": func(b []byte, e *Entry) []byte {
+		return strconv.AppendInt(b, int64(e.Bytes), 10)
+	},
+	"requestID": func(b []byte, e *Entry) []byte {
+		return append(b, e.RequestID...)
+	},
+	"latency": func(b []byte, e *Entry) []byte {
+		return append(b, e.Latency.String()...)
+	},
+	// latencyMs 以毫秒为单位的浮点数输出耗时, 例如 1.234, 便于统计分位数
+	"latencyMs": func(b []byte, e *Entry) []byte {
+		return strconv.AppendFloat(b, float64(e.Latency)/float64(time.Millisecond), 'f', 3, 64)
+	},
+	// latencyUs 以微秒为单位的整数输出耗时
+	"latencyUs": func(b []byte, e *Entry) []byte {
+		return strconv.AppendInt(b, e.Latency.Microseconds(), 10)
+	},
+}
+
+// segment 是解析后格式的一部分, field 为 nil 时表示原样输出的文本
+type segment struct {
+	text  string
+	field func(b []byte, e *Entry) []byte
+}
+
+// Format 是解析后的日志格式, 可以被多个 goroutine 同时使用
+type Format struct {
+	segs []segment
+}
+
+// ParseFormat 解析格式字符串, 可用的占位符有
+// {clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {requestID} {latency} {latencyMs} {latencyUs}
+// 未知的占位符与未闭合的 { 按原样输出
+func ParseFormat(format string) *Format {
+	var segs []segment
+	for format != "" {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			segs = append(segs, segment{text: format})
+			break
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			segs = append(segs, segment{text: format})
+			break
+		}
+		end += start
+		if start > 0 {
+			segs = append(segs, segment{text: format[:start]})
+		}
+		if field, ok := fields[format[start+1:end]]; ok {
+			segs = append(segs, segment{field: field})
+		} else {
+			segs = append(segs, segment{text: format[start : end+1]})
+		}
+		format = format[end+1:]
+	}
+	return &Format{segs: segs}
+}
+
+// Render 按格式渲染一行日志
+func (f *Format) Render(e *Entry) string {
+	b := make([]byte, 0, 128)
+	for _, seg := range f.segs {
+		if seg.field != nil {
+			b = seg.field(b, e)
+		} else {
+			b = append(b, seg.text...)
+		}
+	}
+	return string(b)
+}
+
+// jsonEntry 是 JSON 格式访问日志的字段
+type jsonEntry struct {
+	ClientIP  string  `json:"clientIP"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	UserAgent string  `json:"userAgent"`
+	Bytes     int     `json:"bytes"`
+	RequestID string  `json:"requestID,omitempty"`
+}
+
+// RenderJSON 将一行访问日志渲染为 JSON 对象
+// 字段依次为 clientIP, method, path, status, latencyMs (毫秒, 保留三位小数), userAgent, bytes, 以及不为空时的 requestID
+func RenderJSON(e *Entry) string {
+	b, err := json.Marshal(jsonEntry{
+		ClientIP:  e.ClientIP,
+		Method:    e.Method,
+		Path:      e.Path,
+		Status:    e.Status,
+		LatencyMs: math.Round(float64(e.Latency)/float64(time.Microsecond)) / 1000,
+		UserAgent: e.UserAgent,
+		Bytes:     e.Bytes,
+		RequestID: e.RequestID,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return string(b)
+}
@@ -0,0 +1,84 @@
+package accesslog
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+var testEntry = Entry{
+	ClientIP:  "192.0.2.1",
+	Method:    "GET",
+	Protocol:  "HTTP/1.1",
+	Path:      "/items",
+	UserAgent: "test-agent",
+	Status:    200,
+	Bytes:     5,
+	RequestID: "req-1",
+	Latency:   1234567 * time.Nanosecond,
+}
+
+func TestFormatRender(t *testing.T) {
+	for _, tc := range []struct {
+		format, want string
+	}{
+		{DefaultFormat, "192.0.2.1 GET HTTP/1.1 /items test-agent 200 5 1.234567ms "},
+		{"{requestID} {status}", "req-1 200"},
+		{"plain text", "plain text"},
+		{"", ""},
+		{"{method} {unknown} {path}", "GET {unknown} /items"},
+		{"{method} {path", "GET {path"},
+		{"{path}}{", "/items}{"},
+		{"{latency} {latencyMs} {latencyUs}", "1.234567ms 1.235 1234"},
+	} {
+		if got := ParseFormat(tc.format).Render(&testEntry); got != tc.want {
+			t.Errorf("format %q: got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+
+	f := ParseFormat("{latency} {latencyMs} {latencyUs}")
+	for _, tc := range []struct {
+		latency time.Duration
+		want    string
+	}{
+		{999 * time.Nanosecond, "999ns 0.001 0"},
+		{2 * time.Second, "2s 2000.000 2000000"},
+	} {
+		if got := f.Render(&Entry{Latency: tc.latency}); got != tc.want {
+			t.Errorf("latency %v: got %q, want %q", tc.latency, got, tc.want)
+		}
+	}
+}
+
+func TestRenderJSON(t *testing.T) {
+	var got map[string]any
+	if err := json.Unmarshal([]byte(RenderJSON(&testEntry)), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"clientIP":  "192.0.2.1",
+		"method":    "GET",
+		"path":      "/items",
+		"status":    float64(200),
+		"latencyMs": 1.235,
+		"userAgent": "test-agent",
+		"bytes":     float64(5),
+		"requestID": "req-1",
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// 没有请求 ID 时省略 requestID 字段
+	e := testEntry
+	e.RequestID = ""
+	got = nil
+	if err := json.Unmarshal([]byte(RenderJSON(&e)), &got); err != nil {
+		t.Fatal(err)
+	}
+	if keys := slices.Sorted(maps.Keys(got)); !slices.Equal(keys, []string{"bytes", "clientIP", "latencyMs", "method", "path", "status", "userAgent"}) {
+		t.Errorf("keys without request ID = %v", keys)
+	}
+}
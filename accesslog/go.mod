@@ -0,0 +1,3 @@
+module github.com/WJQSERVER-STUDIO/go-utils/accesslog
+
+go 1.24.3
@@ -1,8 +1,11 @@
 package copyb
 
 import (
+	"context"
 	"errors"
+	"hash"
 	"io"
+	"math"
 
 	"github.com/valyala/bytebufferpool"
 )
@@ -11,6 +14,24 @@ import (
 // 这是对 io 包中未导出的同名错误的本地实现, 以保持兼容性.
 var errInvalidWrite = errors.New("invalid write result")
 
+// ErrLimitExceeded 表示 ReadAllLimit 的数据源超出了允许读取的最大字节数.
+var ErrLimitExceeded = errors.New("copyb: read limit exceeded")
+
+// defaultBufSize 是池化缓冲区的默认大小, 适合大多数高效 I/O 操作.
+const defaultBufSize = 32 * 1024
+
+// getBuffer 从池中获取一个容量至少为 size 的 ByteBuffer, 使用完毕后需要归还到池中.
+func getBuffer(size int) *bytebufferpool.ByteBuffer {
+	bb := bytebufferpool.Get()
+	// 如果池中缓冲区的容量小于所需大小, 则进行一次“投资性”分配,
+	// 将其内部的切片替换为一个更大的切片.
+	// 这可以“升级”池中的小缓冲区, 使未来的复用更高效.
+	if cap(bb.B) < size {
+		bb.B = make([]byte, size)
+	}
+	return bb
+}
+
 // copyBuffer 是 Copy 和 CopyBuffer 的核心实现.
 // 如果 buf 为 nil, 则从 bytebufferpool 中获取一个进行池化操作.
 func copyBuffer(dst io.Writer, src io.Reader, buf []byte) (written int64, err error) {
@@ -25,28 +46,28 @@ func copyBuffer(dst io.Writer, src io.Reader, buf []byte) (written int64, err er
 		return rf.ReadFrom(src)
 	}
 
+	return copyLoop(nil, dst, src, buf, nil)
+}
+
+// copyLoop 是不经过快速路径的缓冲区拷贝循环.
+// 如果 buf 为 nil, 则从 bytebufferpool 中获取一个; 如果 ctx 不为 nil, 每次读取前都会检查它是否已取消;
+// 如果 progress 不为 nil, 每次成功写入后都会以累计写入的字节数调用它.
+func copyLoop(ctx context.Context, dst io.Writer, src io.Reader, buf []byte, progress func(written int64)) (written int64, err error) {
 	// 如果外部没有提供缓冲区, 我们从池中获取一个.
 	if buf == nil {
-		// 定义一个进行高效I/O操作的理想缓冲区大小.
-		const defaultBufSize = 32 * 1024
-
-		// 从池中获取一个 ByteBuffer 对象.
-		bb := bytebufferpool.Get()
+		bb := getBuffer(defaultBufSize)
 		defer bytebufferpool.Put(bb)
-
-		// 检查池中获取的缓冲区容量.
-		// 如果容量小于我们的理想大小, 则进行一次“投资性”分配,
-		// 将其内部的切片替换为一个更大的切片.
-		// 这可以“升级”池中的小缓冲区, 使未来的复用更高效.
-		if cap(bb.B) < defaultBufSize {
-			bb.B = make([]byte, defaultBufSize)
-		}
-
 		buf = bb.B[:defaultBufSize]
 	}
 
 	// 核心拷贝循环, 逻辑与标准库 io.Copy 保持一致, 保证健壮性.
 	for {
+		// 每次读取前检查上下文, 取消时返回已拷贝的字节数和上下文的错误.
+		if ctx != nil {
+			if err = ctx.Err(); err != nil {
+				break
+			}
+		}
 		// 从源读取数据到缓冲区.
 		nr, er := src.Read(buf)
 		if nr > 0 {
@@ -70,6 +91,9 @@ func copyBuffer(dst io.Writer, src io.Reader, buf []byte) (written int64, err er
 				err = io.ErrShortWrite
 				break
 			}
+			if progress != nil {
+				progress(written)
+			}
 		}
 		// 如果读取时发生错误.
 		if er != nil {
@@ -99,6 +123,168 @@ func Copy(dst io.Writer, src io.Reader) (written int64, err error) {
 	return copyBuffer(dst, src, nil)
 }
 
+// CopyBufferSize 类似于 Copy, 但使用大小为 size 的池化缓冲区.
+// 对于超大文件的顺序传输, 较大的缓冲区 (例如 1 MiB) 可以明显减少系统调用次数.
+// 如果 size 不是正数, 使用默认的 32 KiB.
+// 与 Copy 相同, 如果 src 实现了 io.WriterTo 或 dst 实现了 io.ReaderFrom, 将直接使用它们.
+func CopyBufferSize(dst io.Writer, src io.Reader, size int) (written int64, err error) {
+	if size <= 0 {
+		size = defaultBufSize
+	}
+	if wt, ok := src.(io.WriterTo); ok {
+		return wt.WriteTo(dst)
+	}
+	if rf, ok := dst.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+
+	bb := getBuffer(size)
+	defer bytebufferpool.Put(bb)
+	return copyLoop(nil, dst, src, bb.B[:size], nil)
+}
+
+// checksumWriter 将写入的数据同时交给 dst 和 h, 只有写入 dst 成功的部分才会计入校验和.
+type checksumWriter struct {
+	dst io.Writer
+	h   hash.Hash
+}
+
+func (w checksumWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	if n > 0 && n <= len(p) {
+		// hash.Hash 的 Write 永远不会返回错误.
+		w.h.Write(p[:n])
+	}
+	return n, err
+}
+
+// CopyChecksum 类似于 Copy, 但在拷贝的同时将写入 dst 的数据交给 h,
+// 拷贝结束后调用方可以通过 h.Sum(nil) 获得校验和, 而无需再读取一遍数据.
+// 与 io.TeeReader 加 io.Copy 的组合相比, 它只使用一个池化的缓冲区.
+// 如果 src 实现了 io.WriterTo, 仍会直接使用它, 数据同样会经过 h.
+func CopyChecksum(dst io.Writer, src io.Reader, h hash.Hash) (written int64, err error) {
+	return copyBuffer(checksumWriter{dst: dst, h: h}, src, nil)
+}
+
+// teeReader 是 NewTeeReader 返回的 Reader.
+type teeReader struct {
+	r io.Reader
+	w io.Writer
+}
+
+// NewTeeReader 类似于 io.TeeReader, 返回一个在读取 r 的同时将读到的数据写入 w 的 Reader.
+// 每次 Read 都会先把读到的数据写入 w 再返回, 写入 w 出错时该错误会作为读取错误返回.
+// 它不实现 io.WriterTo, 因此交给 Copy 时会使用池化的缓冲区, 而不会额外分配.
+func NewTeeReader(r io.Reader, w io.Writer) io.Reader {
+	return &teeReader{r: r, w: w}
+}
+
+func (t *teeReader) Read(p []byte) (n int, err error) {
+	n, err = t.r.Read(p)
+	if n > 0 {
+		if n, err := t.w.Write(p[:n]); err != nil {
+			return n, err
+		}
+	}
+	return
+}
+
+// CopyContext 类似于 Copy, 但可以通过 ctx 中止拷贝.
+// 每次从 src 读取之前都会检查 ctx, 取消时返回已拷贝的字节数和 ctx.Err().
+// 为了让取消检查生效, CopyContext 不会使用 io.WriterTo 与 io.ReaderFrom 快速路径, 总是走池化缓冲区的拷贝循环.
+// 注意一次阻塞中的 Read 或 Write 不会被打断, 取消只在两次读取之间生效.
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader) (written int64, err error) {
+	return copyLoop(ctx, dst, src, nil, nil)
+}
+
+// CopyWithProgress 类似于 Copy, 但每次成功写入一个缓冲区后都会以累计写入的字节数调用 fn,
+// 适合在上传等场景中展示进度而无需包装 dst.
+// fn 在拷贝所在的 goroutine 中同步调用, 耗时较长的 fn 只会减慢拷贝的节奏.
+// 为了保证回调能够按缓冲区触发, CopyWithProgress 不会使用 io.WriterTo 与 io.ReaderFrom 快速路径.
+func CopyWithProgress(dst io.Writer, src io.Reader, fn func(written int64)) (written int64, err error) {
+	return copyLoop(nil, dst, src, nil, fn)
+}
+
+// CopyAdaptive 使用的缓冲区大小范围与调整阈值.
+const (
+	adaptiveMinBufSize  = 32 * 1024   // 初始 (最小) 缓冲区大小
+	adaptiveMaxBufSize  = 1024 * 1024 // 缓冲区大小上限
+	adaptiveGrowAfter   = 4           // 连续读满这么多次后扩大缓冲区
+	adaptiveShrinkAfter = 4           // 连续读不到一半这么多次后缩小缓冲区
+)
+
+// CopyAdaptive 类似于 Copy, 但会根据实际读取情况自动调整池化缓冲区的大小.
+// 缓冲区从 32 KiB 开始, 连续读满时成倍扩大, 最大到 1 MiB; 连续读不到一半时成倍缩小.
+// 适合长时间、大流量的拷贝, 调用方无需自行估算缓冲区大小.
+// 与 Copy 相同, 如果 src 实现了 io.WriterTo 或 dst 实现了 io.ReaderFrom, 将直接使用它们.
+func CopyAdaptive(dst io.Writer, src io.Reader) (written int64, err error) {
+	if wt, ok := src.(io.WriterTo); ok {
+		return wt.WriteTo(dst)
+	}
+	if rf, ok := dst.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+
+	size := adaptiveMinBufSize
+	bb := getBuffer(size)
+	defer bytebufferpool.Put(bb)
+
+	// full 与 short 分别记录连续读满和连续读不到一半的次数.
+	full, short := 0, 0
+	for {
+		buf := bb.B[:size]
+		nr, er := src.Read(buf)
+		if nr > 0 {
+			nw, ew := dst.Write(buf[0:nr])
+			if nw < 0 || nr < nw {
+				nw = 0
+				if ew == nil {
+					ew = errInvalidWrite
+				}
+			}
+			written += int64(nw)
+			if ew != nil {
+				err = ew
+				break
+			}
+			if nr != nw {
+				err = io.ErrShortWrite
+				break
+			}
+		}
+		if er != nil {
+			if er != io.EOF {
+				err = er
+			}
+			break
+		}
+
+		// 根据本次读取量调整下一次使用的缓冲区大小.
+		switch {
+		case nr == size:
+			short = 0
+			if full++; full >= adaptiveGrowAfter && size < adaptiveMaxBufSize {
+				size *= 2
+				full = 0
+				// 扩大时替换为更大的切片, 归还后池中的缓冲区也随之"升级".
+				if cap(bb.B) < size {
+					bb.B = make([]byte, size)
+				}
+			}
+		case nr < size/2:
+			full = 0
+			if short++; short >= adaptiveShrinkAfter && size > adaptiveMinBufSize {
+				// 缩小只需使用切片的前半部分, 不需要重新分配.
+				size /= 2
+				short = 0
+			}
+		default:
+			full, short = 0, 0
+		}
+	}
+	return written, err
+}
+
 // CopyN 从 src 拷贝 n 字节数据到 dst (或在遇到错误时提前停止).
 // 它返回拷贝的字节数和拷贝时遇到的第一个错误.
 // 仅当 err == nil 时, written == n 才会成立.
@@ -149,3 +335,28 @@ func ReadAll(r io.Reader) ([]byte, error) {
 	copy(b, bb.B)
 	return b, nil
 }
+
+// ReadAllLimit 类似于 ReadAll, 但最多只读取 max 字节.
+// 如果 r 产生的数据超过 max 字节, 返回 ErrLimitExceeded, 适合读取不可信的请求体等场景.
+// 无论成功与否, 池化的缓冲区都会被回收.
+func ReadAllLimit(r io.Reader, max int64) ([]byte, error) {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	// 多读取一个字节, 用于判断数据源是否还有更多数据.
+	limit := max
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	_, err := bb.ReadFrom(io.LimitReader(r, limit))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(bb.B)) > max {
+		return nil, ErrLimitExceeded
+	}
+
+	b := make([]byte, len(bb.B))
+	copy(b, bb.B)
+	return b, nil
+}
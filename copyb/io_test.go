@@ -2,10 +2,15 @@ package copyb
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 // testSource 是一个用于测试的字符串, 包含了各种字符.
@@ -227,3 +232,236 @@ func BenchmarkCopybReadAll(b *testing.B) {
 		}
 	}
 }
+
+// TestCopyAdaptive 测试 CopyAdaptive 在缓冲区扩大和缩小的过程中都能完整拷贝数据.
+func TestCopyAdaptive(t *testing.T) {
+	// 前半部分一次性读满缓冲区以触发扩大, 后半部分每次只返回少量数据以触发缩小.
+	large := bytes.Repeat([]byte(testSource), 64*1024)
+	src := io.MultiReader(
+		struct{ io.Reader }{bytes.NewReader(large)},
+		iotest.OneByteReader(strings.NewReader(testSource)),
+		struct{ io.Reader }{bytes.NewReader(large)},
+	)
+	dst := new(bytes.Buffer)
+
+	written, err := CopyAdaptive(struct{ io.Writer }{dst}, src)
+	if err != nil {
+		t.Fatalf("CopyAdaptive failed: %v", err)
+	}
+
+	want := int64(2*len(large) + len(testSource))
+	if written != want {
+		t.Errorf("Expected to write %d bytes, but wrote %d", want, written)
+	}
+	if !bytes.Equal(dst.Bytes()[:len(large)], large) || dst.String()[len(large):len(large)+len(testSource)] != testSource {
+		t.Errorf("Copied content does not match source")
+	}
+}
+
+// hugeSource 用于大数据量拷贝的基准测试.
+var hugeSource = bytes.Repeat([]byte("0123456789abcdef"), 4*1024*1024) // 64MB
+
+// openHugeFile 将 hugeSource 写入临时文件并打开, 每次 Read 都是一次真实的系统调用.
+func openHugeFile(b *testing.B) *os.File {
+	path := filepath.Join(b.TempDir(), "huge")
+	if err := os.WriteFile(path, hugeSource, 0o600); err != nil {
+		b.Fatal(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { f.Close() })
+	return f
+}
+
+// benchmarkCopyLarge 从文件拷贝大数据量, 源和目标都被包装以屏蔽 io.WriterTo 与 io.ReaderFrom, 强制走缓冲区路径.
+func benchmarkCopyLarge(b *testing.B, copyFn func(io.Writer, io.Reader) (int64, error)) {
+	f := openHugeFile(b)
+	dst := struct{ io.Writer }{io.Discard}
+	b.SetBytes(int64(len(hugeSource)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		f.Seek(0, io.SeekStart)
+		if _, err := copyFn(dst, struct{ io.Reader }{f}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCopybCopyLarge 是固定 32KB 缓冲区拷贝大数据量的性能基准.
+func BenchmarkCopybCopyLarge(b *testing.B) {
+	benchmarkCopyLarge(b, Copy)
+}
+
+// BenchmarkCopybCopyAdaptiveLarge 是自适应缓冲区拷贝大数据量的性能基准.
+func BenchmarkCopybCopyAdaptiveLarge(b *testing.B) {
+	benchmarkCopyLarge(b, CopyAdaptive)
+}
+
+// cancelWriter 在写入指定字节数后调用 cancel.
+type cancelWriter struct {
+	bytes.Buffer
+	after  int
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if w.Len() >= w.after {
+		w.cancel()
+	}
+	return n, err
+}
+
+// TestCopyContext 测试 CopyContext 在上下文取消后停止拷贝并返回已拷贝的字节数.
+func TestCopyContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dst := &cancelWriter{after: 1, cancel: cancel}
+
+	// strings.Reader 实现了 io.WriterTo, CopyContext 不应走快速路径一次性拷贝完.
+	src := strings.NewReader(strings.Repeat(testSource, 1024))
+	written, err := CopyContext(ctx, dst, src)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if written == 0 || written >= src.Size() || written != int64(dst.Len()) {
+		t.Errorf("Expected a partial copy, wrote %d of %d bytes (dst has %d)", written, src.Size(), dst.Len())
+	}
+
+	// 已取消的上下文不会读取任何数据.
+	dst.Reset()
+	written, err = CopyContext(ctx, dst, strings.NewReader(testSource))
+	if !errors.Is(err, context.Canceled) || written != 0 || dst.Len() != 0 {
+		t.Errorf("Expected no copy after cancel, got %d bytes, err %v", written, err)
+	}
+}
+
+// TestCopyWithProgress 测试 CopyWithProgress 每次写入后都以递增的累计字节数调用回调.
+func TestCopyWithProgress(t *testing.T) {
+	src := strings.NewReader(strings.Repeat(testSource, 1024))
+	dst := new(bytes.Buffer)
+
+	var calls []int64
+	written, err := CopyWithProgress(dst, src, func(n int64) {
+		calls = append(calls, n)
+	})
+	if err != nil {
+		t.Fatalf("CopyWithProgress failed: %v", err)
+	}
+	if written != src.Size() || dst.Len() != int(src.Size()) {
+		t.Errorf("Expected to write %d bytes, but wrote %d", src.Size(), written)
+	}
+	if len(calls) < 2 {
+		t.Fatalf("Expected progress per buffer, got %d calls", len(calls))
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i] <= calls[i-1] {
+			t.Fatalf("Progress is not increasing: %v", calls)
+		}
+	}
+	if calls[len(calls)-1] != written {
+		t.Errorf("Last progress %d does not match written %d", calls[len(calls)-1], written)
+	}
+}
+
+// TestReadAllLimit 测试 ReadAllLimit 在数据不超过上限时完整读取, 超过上限时返回 ErrLimitExceeded.
+func TestReadAllLimit(t *testing.T) {
+	data, err := ReadAllLimit(strings.NewReader(testSource), int64(len(testSource)))
+	if err != nil {
+		t.Fatalf("ReadAllLimit failed: %v", err)
+	}
+	if string(data) != testSource {
+		t.Errorf("ReadAllLimit content mismatch. Got %q, want %q", data, testSource)
+	}
+
+	data, err = ReadAllLimit(strings.NewReader(testSource), int64(len(testSource)-1))
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Fatalf("Expected ErrLimitExceeded, got %v", err)
+	}
+	if data != nil {
+		t.Errorf("Expected nil data on error, got %d bytes", len(data))
+	}
+}
+
+// TestCopyBufferSize 测试 CopyBufferSize 使用自定义大小与非法大小时都能完整拷贝数据.
+func TestCopyBufferSize(t *testing.T) {
+	for _, size := range []int{16, 0, -1, 1024 * 1024} {
+		src := struct{ io.Reader }{strings.NewReader(testSource)}
+		dst := new(bytes.Buffer)
+
+		written, err := CopyBufferSize(struct{ io.Writer }{dst}, src, size)
+		if err != nil {
+			t.Fatalf("CopyBufferSize(%d) failed: %v", size, err)
+		}
+		if written != int64(len(testSource)) || dst.String() != testSource {
+			t.Errorf("CopyBufferSize(%d) copied %d bytes %q, want %q", size, written, dst.String(), testSource)
+		}
+	}
+}
+
+// BenchmarkCopybCopyBufferSize32KLarge 是 32KB 缓冲区拷贝大数据量的性能基准.
+func BenchmarkCopybCopyBufferSize32KLarge(b *testing.B) {
+	benchmarkCopyLarge(b, func(dst io.Writer, src io.Reader) (int64, error) {
+		return CopyBufferSize(dst, src, 32*1024)
+	})
+}
+
+// BenchmarkCopybCopyBufferSize1MLarge 是 1MB 缓冲区拷贝大数据量的性能基准.
+func BenchmarkCopybCopyBufferSize1MLarge(b *testing.B) {
+	benchmarkCopyLarge(b, func(dst io.Writer, src io.Reader) (int64, error) {
+		return CopyBufferSize(dst, src, 1024*1024)
+	})
+}
+
+// TestCopyChecksum 测试 CopyChecksum 的拷贝结果和校验和都与源数据一致.
+func TestCopyChecksum(t *testing.T) {
+	data := strings.Repeat(testSource, 1024)
+	want := sha256.Sum256([]byte(data))
+
+	// 分别覆盖 io.WriterTo 快速路径和缓冲区路径.
+	for _, src := range []io.Reader{strings.NewReader(data), struct{ io.Reader }{strings.NewReader(data)}} {
+		dst := new(bytes.Buffer)
+		h := sha256.New()
+		written, err := CopyChecksum(dst, src, h)
+		if err != nil {
+			t.Fatalf("CopyChecksum failed: %v", err)
+		}
+		if written != int64(len(data)) || dst.String() != data {
+			t.Errorf("Expected to write %d bytes, but wrote %d", len(data), written)
+		}
+		if !bytes.Equal(h.Sum(nil), want[:]) {
+			t.Errorf("Checksum mismatch. Got %x, want %x", h.Sum(nil), want)
+		}
+	}
+}
+
+// TestTeeReader 测试 NewTeeReader 与 Copy 组合时两端都得到完整数据, 且写入错误会作为读取错误返回.
+func TestTeeReader(t *testing.T) {
+	dst := new(bytes.Buffer)
+	side := new(bytes.Buffer)
+	written, err := Copy(dst, NewTeeReader(strings.NewReader(testSource), side))
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if written != int64(len(testSource)) || dst.String() != testSource || side.String() != testSource {
+		t.Errorf("TeeReader content mismatch. dst %q, side %q", dst.String(), side.String())
+	}
+
+	errWrite := errors.New("write failed")
+	r := NewTeeReader(strings.NewReader(testSource), &errorWriter{err: errWrite})
+	if _, err := r.Read(make([]byte, 8)); !errors.Is(err, errWrite) {
+		t.Errorf("Expected write error, got %v", err)
+	}
+}
+
+// errorWriter 的每次写入都返回指定的错误.
+type errorWriter struct {
+	err error
+}
+
+func (w *errorWriter) Write(p []byte) (int, error) {
+	return 0, w.err
+}
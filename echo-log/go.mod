@@ -0,0 +1,22 @@
+module github.com/WJQSERVER-STUDIO/go-utils/echo-log
+
+go 1.24.3
+
+require (
+	github.com/WJQSERVER-STUDIO/go-utils/accesslog v0.1.0
+	github.com/WJQSERVER-STUDIO/logger v1.6.0
+	github.com/labstack/echo/v4 v4.13.3
+)
+
+require (
+	github.com/WJQSERVER-STUDIO/go-utils/log v0.0.2 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/net v0.33.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
@@ -0,0 +1,137 @@
+package logm
+
+import (
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/accesslog"
+	"github.com/WJQSERVER-STUDIO/logger"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	logw       = logger.Logw
+	logDump    = logger.LogDump
+	logDebug   = logger.LogDebug
+	logInfo    = logger.LogInfo
+	logWarning = logger.LogWarning
+	logError   = logger.LogError
+)
+
+// DefaultFormat 是 Middleware 使用的默认日志格式, 与 gin-log 的字段布局一致
+const DefaultFormat = accesslog.DefaultFormat
+
+// 日志中间件
+func Middleware() echo.MiddlewareFunc {
+	return MiddlewareWithFormat(DefaultFormat)
+}
+
+// MiddlewareWithFormat 返回使用自定义格式的日志中间件
+// format 中的占位符会在每个请求结束后被替换, 可用的占位符有
+// {clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {latency} {latencyMs} {latencyUs} {requestID}, 未知的占位符按原样输出
+// {requestID} 为响应头 X-Request-Id 的值, 需要配合 echo 的 middleware.RequestID 使用
+func MiddlewareWithFormat(format string) echo.MiddlewareFunc {
+	return MiddlewareWithConfig(Config{Format: format})
+}
+
+// Config 是日志中间件的配置
+type Config struct {
+	// Format 是日志格式, 为空时使用 DefaultFormat, 占位符见 MiddlewareWithFormat
+	Format string
+
+	// SkipPaths 中的路径 (完全匹配 URL.Path) 不记录日志, 例如健康检查与监控接口
+	SkipPaths []string
+
+	// Skip 不为 nil 且返回 true 时不记录该请求的日志, 在处理完请求后调用
+	Skip func(c echo.Context) bool
+
+	// JSON 为 true 时每个请求输出一个 JSON 对象, 此时忽略 Format, 字段见 accesslog.RenderJSON
+	JSON bool
+
+	// StatusLevel 根据响应状态码决定日志等级, 为 nil 时使用 DefaultStatusLevel
+	StatusLevel func(status int) Level
+}
+
+// Level 是访问日志使用的 logger 等级
+type Level = accesslog.Level
+
+const (
+	LevelDebug = accesslog.LevelDebug
+	LevelInfo  = accesslog.LevelInfo
+	LevelWarn  = accesslog.LevelWarn
+	LevelError = accesslog.LevelError
+)
+
+// DefaultStatusLevel 是默认的状态码到日志等级的映射: 5xx 为 Error, 4xx 为 Warn, 其余为 Info
+func DefaultStatusLevel(status int) Level {
+	return accesslog.DefaultStatusLevel(status)
+}
+
+// logAt 以指定等级输出一行日志
+func logAt(level Level, line string) {
+	switch level {
+	case LevelDebug:
+		logDebug("%s", line)
+	case LevelWarn:
+		logWarning("%s", line)
+	case LevelError:
+		logError("%s", line)
+	default:
+		logInfo("%s", line)
+	}
+}
+
+// MiddlewareWithConfig 返回使用 cfg 配置的日志中间件
+// 处理函数返回的错误会先交给 c.Error 写出响应, 以便记录到最终的状态码, 之后中间件返回 nil
+func MiddlewareWithConfig(cfg Config) echo.MiddlewareFunc {
+	format := cfg.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+	f := accesslog.ParseFormat(format)
+	statusLevel := cfg.StatusLevel
+	if statusLevel == nil {
+		statusLevel = DefaultStatusLevel
+	}
+	skipPaths := accesslog.NewPathSet(cfg.SkipPaths)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			startTime := time.Now()
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			if skipPaths.Contains(c.Request().URL.Path) {
+				return nil
+			}
+			if cfg.Skip != nil && cfg.Skip(c) {
+				return nil
+			}
+
+			e := newEntry(c, time.Since(startTime))
+			if cfg.JSON {
+				logAt(statusLevel(e.Status), accesslog.RenderJSON(e))
+				return nil
+			}
+			logAt(statusLevel(e.Status), f.Render(e))
+			return nil
+		}
+	}
+}
+
+// newEntry 从 echo.Context 中取出渲染一行访问日志所需的数据
+func newEntry(c echo.Context, latency time.Duration) *accesslog.Entry {
+	req, res := c.Request(), c.Response()
+	return &accesslog.Entry{
+		ClientIP:  c.RealIP(),
+		Method:    req.Method,
+		Protocol:  req.Proto,
+		Path:      req.URL.Path,
+		UserAgent: req.UserAgent(),
+		Status:    res.Status,
+		Bytes:     int(res.Size),
+		RequestID: res.Header().Get(echo.HeaderXRequestID),
+		Latency:   latency,
+	}
+}
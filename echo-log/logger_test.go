@@ -0,0 +1,103 @@
+package logm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// logLine 是一行被捕获的日志及其等级
+type logLine struct {
+	level Level
+	line  string
+}
+
+// captureLogs 将 logDebug/logInfo/logWarning/logError 替换为记录到返回的切片中, 测试结束后恢复
+func captureLogs(t *testing.T) *[]logLine {
+	t.Helper()
+	var lines []logLine
+	origDebug, origInfo, origWarning, origError := logDebug, logInfo, logWarning, logError
+	record := func(level Level) func(string, ...interface{}) {
+		return func(format string, args ...interface{}) {
+			lines = append(lines, logLine{level, fmt.Sprintf(format, args...)})
+		}
+	}
+	logDebug, logInfo, logWarning, logError = record(LevelDebug), record(LevelInfo), record(LevelWarn), record(LevelError)
+	t.Cleanup(func() {
+		logDebug, logInfo, logWarning, logError = origDebug, origInfo, origWarning, origError
+	})
+	return &lines
+}
+
+// serve 使用 mw 与一个处理 path 的 handler 构造路由, 发送一个 GET 请求并返回响应
+func serve(path string, handler echo.HandlerFunc, mw echo.MiddlewareFunc) *httptest.ResponseRecorder {
+	e := echo.New()
+	e.Use(mw)
+	e.GET(path, handler)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("User-Agent", "test-agent")
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	hello := func(c echo.Context) error { return c.String(http.StatusOK, "hello") }
+	for _, tc := range []struct {
+		name    string
+		handler echo.HandlerFunc
+		cfg     Config
+		want    logLine
+	}{
+		{"format", hello, Config{Format: "{clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {unknown}"},
+			logLine{LevelInfo, "192.0.2.1 GET HTTP/1.1 /items test-agent 200 5 {unknown}"}},
+		{"requestID", func(c echo.Context) error {
+			c.Response().Header().Set(echo.HeaderXRequestID, "req-1")
+			return c.NoContent(http.StatusNoContent)
+		}, Config{Format: "{requestID} {bytes}"}, logLine{LevelInfo, "req-1 0"}},
+		// 返回的错误由 c.Error 写出, 访问日志记录最终的状态码
+		{"returned error", func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}, Config{Format: "{status}"}, logLine{LevelWarn, "404"}},
+		{"custom level", func(c echo.Context) error {
+			return c.NoContent(http.StatusBadGateway)
+		}, Config{Format: "{status}", StatusLevel: func(int) Level { return LevelDebug }}, logLine{LevelDebug, "502"}},
+	} {
+		lines := captureLogs(t)
+		serve("/items", tc.handler, MiddlewareWithConfig(tc.cfg))
+		if len(*lines) != 1 || (*lines)[0] != tc.want {
+			t.Errorf("%s: logged %+v, want [%+v]", tc.name, *lines, tc.want)
+		}
+	}
+}
+
+func TestMiddlewareSkipAndJSON(t *testing.T) {
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "hello") }
+	mw := MiddlewareWithConfig(Config{
+		JSON:      true,
+		SkipPaths: []string{"/healthz"},
+		Skip:      func(c echo.Context) bool { return c.Get("quiet") == true },
+	})
+
+	lines := captureLogs(t)
+	serve("/healthz", ok, mw)
+	serve("/quiet", func(c echo.Context) error { c.Set("quiet", true); return nil }, mw)
+	serve("/items", ok, mw)
+	if len(*lines) != 1 {
+		t.Fatalf("logged %+v, want only /items", *lines)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte((*lines)[0].line), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["clientIP"] != "192.0.2.1" || got["path"] != "/items" || got["status"] != float64(200) || got["bytes"] != float64(5) {
+		t.Errorf("got %v", got)
+	}
+	if _, ok := got["requestID"]; ok {
+		t.Errorf("requestID without a request ID header: %v", got)
+	}
+}
@@ -0,0 +1,156 @@
+package logm
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/accesslog"
+	"github.com/WJQSERVER-STUDIO/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	logw       = logger.Logw
+	logDump    = logger.LogDump
+	logDebug   = logger.LogDebug
+	logInfo    = logger.LogInfo
+	logWarning = logger.LogWarning
+	logError   = logger.LogError
+)
+
+// DefaultFormat 是 Middleware 使用的默认日志格式, 与 gin-log 的字段布局一致
+const DefaultFormat = accesslog.DefaultFormat
+
+// 日志中间件
+func Middleware() fiber.Handler {
+	return MiddlewareWithFormat(DefaultFormat)
+}
+
+// MiddlewareWithFormat 返回使用自定义格式的日志中间件
+// format 中的占位符会在每个请求结束后被替换, 可用的占位符有
+// {clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {latency} {latencyMs} {latencyUs} {requestID}, 未知的占位符按原样输出
+// {requestID} 为响应头 X-Request-ID 的值, 需要配合 fiber 的 middleware/requestid 使用
+func MiddlewareWithFormat(format string) fiber.Handler {
+	return MiddlewareWithConfig(Config{Format: format})
+}
+
+// Config 是日志中间件的配置
+type Config struct {
+	// Format 是日志格式, 为空时使用 DefaultFormat, 占位符见 MiddlewareWithFormat
+	Format string
+
+	// SkipPaths 中的路径 (完全匹配 c.Path()) 不记录日志, 例如健康检查与监控接口
+	SkipPaths []string
+
+	// Skip 不为 nil 且返回 true 时不记录该请求的日志, 在处理完请求后调用
+	Skip func(c *fiber.Ctx) bool
+
+	// JSON 为 true 时每个请求输出一个 JSON 对象, 此时忽略 Format, 字段见 accesslog.RenderJSON
+	JSON bool
+
+	// StatusLevel 根据响应状态码决定日志等级, 为 nil 时使用 DefaultStatusLevel
+	StatusLevel func(status int) Level
+}
+
+// Level 是访问日志使用的 logger 等级
+type Level = accesslog.Level
+
+const (
+	LevelDebug = accesslog.LevelDebug
+	LevelInfo  = accesslog.LevelInfo
+	LevelWarn  = accesslog.LevelWarn
+	LevelError = accesslog.LevelError
+)
+
+// DefaultStatusLevel 是默认的状态码到日志等级的映射: 5xx 为 Error, 4xx 为 Warn, 其余为 Info
+func DefaultStatusLevel(status int) Level {
+	return accesslog.DefaultStatusLevel(status)
+}
+
+// logAt 以指定等级输出一行日志
+func logAt(level Level, line string) {
+	switch level {
+	case LevelDebug:
+		logDebug("%s", line)
+	case LevelWarn:
+		logWarning("%s", line)
+	case LevelError:
+		logError("%s", line)
+	default:
+		logInfo("%s", line)
+	}
+}
+
+// MiddlewareWithConfig 返回使用 cfg 配置的日志中间件
+// 后续处理函数返回的错误会先交给 App 的 ErrorHandler 写出响应, 以便记录到最终的状态码, 之后中间件返回 nil
+func MiddlewareWithConfig(cfg Config) fiber.Handler {
+	format := cfg.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+	f := accesslog.ParseFormat(format)
+	statusLevel := cfg.StatusLevel
+	if statusLevel == nil {
+		statusLevel = DefaultStatusLevel
+	}
+	skipPaths := accesslog.NewPathSet(cfg.SkipPaths)
+
+	return func(c *fiber.Ctx) error {
+		startTime := time.Now()
+
+		if err := c.Next(); err != nil {
+			if herr := c.App().ErrorHandler(c, err); herr != nil {
+				c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+
+		// fiber 的 Ctx 返回的字符串在请求结束后会被复用, 因此在中间件返回前完成渲染
+		if skipPaths.Contains(c.Path()) {
+			return nil
+		}
+		if cfg.Skip != nil && cfg.Skip(c) {
+			return nil
+		}
+
+		e := newEntry(c, time.Since(startTime))
+		if cfg.JSON {
+			logAt(statusLevel(e.Status), accesslog.RenderJSON(e))
+			return nil
+		}
+		logAt(statusLevel(e.Status), f.Render(e))
+		return nil
+	}
+}
+
+// newEntry 从 fiber.Ctx 中取出渲染一行访问日志所需的数据
+func newEntry(c *fiber.Ctx, latency time.Duration) *accesslog.Entry {
+	return &accesslog.Entry{
+		ClientIP:  c.IP(),
+		Method:    c.Method(),
+		Protocol:  string(c.Request().Header.Protocol()),
+		Path:      c.Path(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Status:    c.Response().StatusCode(),
+		Bytes:     responseSize(c),
+		RequestID: c.GetRespHeader(fiber.HeaderXRequestID),
+		Latency:   latency,
+	}
+}
+
+// responseSize 返回响应体的字节数
+// 流式响应体只能使用 Content-Length, 未知时记为 0
+// 1xx, 204, 304 与 HEAD 请求的响应不会发送响应体, 即使 SendStatus 填入了状态描述
+func responseSize(c *fiber.Ctx) int {
+	res := c.Response()
+	if status := res.StatusCode(); status < http.StatusOK || status == http.StatusNoContent ||
+		status == http.StatusNotModified || c.Method() == http.MethodHead {
+		return 0
+	}
+	if !res.IsBodyStream() {
+		return len(res.Body())
+	}
+	if size := res.Header.ContentLength(); size > 0 {
+		return size
+	}
+	return 0
+}
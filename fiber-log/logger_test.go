@@ -0,0 +1,113 @@
+package logm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// logLine 是一行被捕获的日志及其等级
+type logLine struct {
+	level Level
+	line  string
+}
+
+// captureLogs 将 logDebug/logInfo/logWarning/logError 替换为记录到返回的切片中, 测试结束后恢复
+func captureLogs(t *testing.T) *[]logLine {
+	t.Helper()
+	var lines []logLine
+	origDebug, origInfo, origWarning, origError := logDebug, logInfo, logWarning, logError
+	record := func(level Level) func(string, ...interface{}) {
+		return func(format string, args ...interface{}) {
+			lines = append(lines, logLine{level, fmt.Sprintf(format, args...)})
+		}
+	}
+	logDebug, logInfo, logWarning, logError = record(LevelDebug), record(LevelInfo), record(LevelWarn), record(LevelError)
+	t.Cleanup(func() {
+		logDebug, logInfo, logWarning, logError = origDebug, origInfo, origWarning, origError
+	})
+	return &lines
+}
+
+// serve 使用 mw 与一个处理 path 的 handler 构造应用, 发送一个 GET 请求并返回响应
+func serve(t *testing.T, path string, handler fiber.Handler, mw fiber.Handler) *http.Response {
+	t.Helper()
+	app := fiber.New()
+	app.Use(mw)
+	app.Get(path, handler)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("User-Agent", "test-agent")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestMiddleware(t *testing.T) {
+	hello := func(c *fiber.Ctx) error { return c.SendString("hello") }
+	for _, tc := range []struct {
+		name    string
+		handler fiber.Handler
+		cfg     Config
+		want    logLine
+	}{
+		{"format", hello, Config{Format: "{method} {protocol} {path} {userAgent} {status} {bytes} {unknown}"},
+			logLine{LevelInfo, "GET HTTP/1.1 /items test-agent 200 5 {unknown}"}},
+		{"requestID", func(c *fiber.Ctx) error {
+			c.Set(fiber.HeaderXRequestID, "req-1")
+			return c.SendStatus(http.StatusNoContent)
+		}, Config{Format: "{requestID} {bytes}"}, logLine{LevelInfo, "req-1 0"}},
+		// 返回的错误由 ErrorHandler 写出, 访问日志记录最终的状态码
+		{"returned error", func(c *fiber.Ctx) error {
+			return fiber.ErrNotFound
+		}, Config{Format: "{status}"}, logLine{LevelWarn, "404"}},
+		{"stream with length", func(c *fiber.Ctx) error {
+			return c.SendStream(strings.NewReader("hello world"), 11)
+		}, Config{Format: "{bytes}"}, logLine{LevelInfo, "11"}},
+		{"custom level", func(c *fiber.Ctx) error {
+			return c.SendStatus(http.StatusBadGateway)
+		}, Config{Format: "{status}", StatusLevel: func(int) Level { return LevelDebug }}, logLine{LevelDebug, "502"}},
+	} {
+		lines := captureLogs(t)
+		serve(t, "/items", tc.handler, MiddlewareWithConfig(tc.cfg))
+		if len(*lines) != 1 || (*lines)[0] != tc.want {
+			t.Errorf("%s: logged %+v, want [%+v]", tc.name, *lines, tc.want)
+		}
+	}
+}
+
+func TestMiddlewareSkipAndJSON(t *testing.T) {
+	ok := func(c *fiber.Ctx) error { return c.SendString("hello") }
+	mw := MiddlewareWithConfig(Config{
+		JSON:      true,
+		SkipPaths: []string{"/healthz"},
+		Skip:      func(c *fiber.Ctx) bool { return c.Locals("quiet") == true },
+	})
+
+	lines := captureLogs(t)
+	serve(t, "/healthz", ok, mw)
+	serve(t, "/quiet", func(c *fiber.Ctx) error { c.Locals("quiet", true); return nil }, mw)
+	resp := serve(t, "/items", ok, mw)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if len(*lines) != 1 {
+		t.Fatalf("logged %+v, want only /items", *lines)
+	}
+	var got map[string]any
+	if err := json.Unmarshal([]byte((*lines)[0].line), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["method"] != "GET" || got["path"] != "/items" || got["status"] != float64(200) || got["bytes"] != float64(5) {
+		t.Errorf("got %v", got)
+	}
+	if _, ok := got["requestID"]; ok {
+		t.Errorf("requestID without a request ID header: %v", got)
+	}
+}
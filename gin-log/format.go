@@ -0,0 +1,34 @@
+package logm
+
+import (
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/accesslog"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultFormat 是 Middleware 使用的默认日志格式
+const DefaultFormat = accesslog.DefaultFormat
+
+// newEntry 从 gin.Context 中取出渲染一行访问日志所需的数据
+func newEntry(c *gin.Context, latency time.Duration) *accesslog.Entry {
+	return &accesslog.Entry{
+		ClientIP:  c.ClientIP(),
+		Method:    c.Request.Method,
+		Protocol:  c.Request.Header.Get("Protocol"),
+		Path:      c.Request.URL.Path,
+		UserAgent: c.Request.UserAgent(),
+		Status:    c.Writer.Status(),
+		Bytes:     responseSize(c),
+		RequestID: GetRequestID(c),
+		Latency:   latency,
+	}
+}
+
+// responseSize 返回响应体的字节数, gin 在未写入任何数据时返回 -1, 此时记为 0
+func responseSize(c *gin.Context) int {
+	if size := c.Writer.Size(); size > 0 {
+		return size
+	}
+	return 0
+}
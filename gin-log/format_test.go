@@ -0,0 +1,124 @@
+package logm
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMiddlewareWithFormat 测试占位符替换, 以及未知占位符与未闭合的 { 按原样输出
+func TestMiddlewareWithFormat(t *testing.T) {
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "hello") }
+	for _, tc := range []struct {
+		format, want string
+	}{
+		{"{clientIP} {method} {path} {userAgent} {status}", "192.0.2.1 GET /items test-agent 200"},
+		{"plain text", "plain text"},
+		{"{method} {unknown} {path}", "GET {unknown} /items"},
+		{"{method} {path", "GET {path"},
+	} {
+		lines := captureLogs(t)
+		serve("/items", ok, MiddlewareWithFormat(tc.format))
+		if got := onlyLine(t, *lines).line; got != tc.want {
+			t.Errorf("format %q: got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+
+	// 空格式使用 DefaultFormat
+	lines := captureLogs(t)
+	serve("/items", ok, MiddlewareWithConfig(Config{}))
+	if got, prefix := onlyLine(t, *lines).line, "192.0.2.1 GET  /items test-agent 200 5 "; len(got) <= len(prefix) || got[:len(prefix)] != prefix {
+		t.Errorf("default format: got %q, want prefix %q", got, prefix)
+	}
+}
+
+// TestLatencyFields 测试 {latencyMs} 与 {latencyUs} 输出数值, 具体格式见 accesslog 的测试
+func TestLatencyFields(t *testing.T) {
+	lines := captureLogs(t)
+	serve("/", func(c *gin.Context) { time.Sleep(time.Millisecond) }, MiddlewareWithFormat("{latencyMs}|{latencyUs}"))
+	got := onlyLine(t, *lines).line
+	ms, us, ok := strings.Cut(got, "|")
+	if !ok || !regexp.MustCompile(`^\d+\.\d{3}$`).MatchString(ms) || !regexp.MustCompile(`^\d+$`).MatchString(us) {
+		t.Fatalf("got %q, want <ms with 3 decimals>|<integer us>", got)
+	}
+	if n, _ := strconv.Atoi(us); n < 1000 {
+		t.Errorf("latencyUs = %d, want >= 1000 after sleeping 1ms", n)
+	}
+}
+
+// TestMiddlewareJSON 测试 JSON 模式的字段名与取值, requestID 只在使用 RequestID 中间件时输出
+func TestMiddlewareJSON(t *testing.T) {
+	ok := func(c *gin.Context) { c.String(http.StatusCreated, "hello") }
+	for _, tc := range []struct {
+		name        string
+		middlewares []gin.HandlerFunc
+		wantKeys    []string
+	}{
+		{"plain", []gin.HandlerFunc{MiddlewareWithConfig(Config{JSON: true, Format: "{path}"})},
+			[]string{"bytes", "clientIP", "latencyMs", "method", "path", "status", "userAgent"}},
+		{"requestID", []gin.HandlerFunc{RequestID(), MiddlewareWithConfig(Config{JSON: true})},
+			[]string{"bytes", "clientIP", "latencyMs", "method", "path", "requestID", "status", "userAgent"}},
+	} {
+		lines := captureLogs(t)
+		w := serve("/items", ok, tc.middlewares...)
+		var got map[string]any
+		if err := json.Unmarshal([]byte(onlyLine(t, *lines).line), &got); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if keys := slices.Sorted(maps.Keys(got)); !slices.Equal(keys, tc.wantKeys) {
+			t.Errorf("%s: keys = %v, want %v", tc.name, keys, tc.wantKeys)
+		}
+		want := map[string]any{
+			"clientIP":  "192.0.2.1",
+			"method":    "GET",
+			"path":      "/items",
+			"status":    float64(http.StatusCreated),
+			"userAgent": "test-agent",
+			"bytes":     float64(5),
+		}
+		for k, v := range want {
+			if got[k] != v {
+				t.Errorf("%s: %s = %v, want %v", tc.name, k, got[k], v)
+			}
+		}
+		if id, ok := got["requestID"]; ok && id != w.Header().Get(RequestIDHeader) {
+			t.Errorf("%s: requestID = %v, want %q", tc.name, id, w.Header().Get(RequestIDHeader))
+		}
+		if ms, _ := got["latencyMs"].(float64); ms < 0 {
+			t.Errorf("%s: latencyMs = %v", tc.name, got["latencyMs"])
+		}
+	}
+}
+
+// TestBytesField 测试 {bytes} 输出响应体字节数, gin 未写入响应体时报告 -1, 此时记为 0
+func TestBytesField(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler gin.HandlerFunc
+		want    string
+	}{
+		{"body", func(c *gin.Context) { c.String(http.StatusOK, "hello") }, "5"},
+		{"no body", func(c *gin.Context) { c.Status(http.StatusNoContent) }, "0"},
+		{"streamed", func(c *gin.Context) {
+			for range 3 {
+				io.WriteString(c.Writer, "chunk")
+				c.Writer.Flush()
+			}
+		}, "15"},
+	} {
+		lines := captureLogs(t)
+		serve("/", tc.handler, MiddlewareWithFormat("{bytes}"))
+		if got := onlyLine(t, *lines).line; got != tc.want {
+			t.Errorf("%s: {bytes} = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ module github.com/WJQSERVER-STUDIO/go-utils/gin-log
 go 1.24.3
 
 require (
+	github.com/WJQSERVER-STUDIO/go-utils/accesslog v0.1.0
 	github.com/WJQSERVER-STUDIO/logger v1.6.0
 	github.com/gin-gonic/gin v1.10.0
 )
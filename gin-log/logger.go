@@ -3,6 +3,7 @@ package logm
 import (
 	"time"
 
+	"github.com/WJQSERVER-STUDIO/go-utils/accesslog"
 	"github.com/WJQSERVER-STUDIO/logger"
 	"github.com/gin-gonic/gin"
 )
@@ -18,14 +19,101 @@ var (
 
 // 日志中间件
 func Middleware() gin.HandlerFunc {
+	return MiddlewareWithFormat(DefaultFormat)
+}
+
+// MiddlewareWithFormat 返回使用自定义格式的日志中间件
+// format 中的占位符会在每个请求结束后被替换, 可用的占位符有
+// {clientIP} {method} {protocol} {path} {userAgent} {status} {bytes} {latency} {requestID}, 未知的占位符按原样输出
+// {bytes} 为响应体的字节数, 未写入响应体时为 0
+// {requestID} 需要配合 RequestID 中间件使用
+// {latency} 为便于阅读的时长 (例如 1.234ms), 需要数值时可以使用 {latencyMs} (毫秒, 保留三位小数) 或 {latencyUs} (微秒, 整数)
+func MiddlewareWithFormat(format string) gin.HandlerFunc {
+	return MiddlewareWithConfig(Config{Format: format})
+}
+
+// Config 是日志中间件的配置
+type Config struct {
+	// Format 是日志格式, 为空时使用 DefaultFormat, 占位符见 MiddlewareWithFormat
+	Format string
+
+	// SkipPaths 中的路径 (完全匹配 URL.Path) 不记录日志, 例如健康检查与监控接口
+	SkipPaths []string
+
+	// Skip 不为 nil 且返回 true 时不记录该请求的日志, 在处理完请求后调用
+	Skip func(c *gin.Context) bool
+
+	// JSON 为 true 时每个请求输出一个 JSON 对象, 此时忽略 Format
+	// 字段依次为 clientIP, method, path, status, latencyMs (毫秒, 保留三位小数), userAgent, bytes (响应体字节数),
+	// 以及使用 RequestID 中间件时的 requestID
+	JSON bool
+
+	// StatusLevel 根据响应状态码决定日志等级, 为 nil 时使用 DefaultStatusLevel
+	StatusLevel func(status int) Level
+}
+
+// Level 是访问日志使用的 logger 等级
+type Level = accesslog.Level
+
+const (
+	LevelDebug = accesslog.LevelDebug
+	LevelInfo  = accesslog.LevelInfo
+	LevelWarn  = accesslog.LevelWarn
+	LevelError = accesslog.LevelError
+)
+
+// DefaultStatusLevel 是默认的状态码到日志等级的映射: 5xx 为 Error, 4xx 为 Warn, 其余为 Info
+func DefaultStatusLevel(status int) Level {
+	return accesslog.DefaultStatusLevel(status)
+}
+
+// logAt 以指定等级输出一行日志
+func logAt(level Level, line string) {
+	switch level {
+	case LevelDebug:
+		logDebug("%s", line)
+	case LevelWarn:
+		logWarning("%s", line)
+	case LevelError:
+		logError("%s", line)
+	default:
+		logInfo("%s", line)
+	}
+}
+
+// MiddlewareWithConfig 返回使用 cfg 配置的日志中间件
+func MiddlewareWithConfig(cfg Config) gin.HandlerFunc {
+	format := cfg.Format
+	if format == "" {
+		format = DefaultFormat
+	}
+	f := accesslog.ParseFormat(format)
+	statusLevel := cfg.StatusLevel
+	if statusLevel == nil {
+		statusLevel = DefaultStatusLevel
+	}
+	skipPaths := accesslog.NewPathSet(cfg.SkipPaths)
+
 	return func(c *gin.Context) {
 		startTime := time.Now()
 
 		c.Next()
 
+		if skipPaths.Contains(c.Request.URL.Path) {
+			return
+		}
+		if cfg.Skip != nil && cfg.Skip(c) {
+			return
+		}
+
 		endTime := time.Now()
 		timingResults := endTime.Sub(startTime)
 
-		logInfo("%s %s %s %s %s %d %s ", c.ClientIP(), c.Request.Method, c.Request.Header.Get("Protocol"), c.Request.URL.Path, c.Request.UserAgent(), c.Writer.Status(), timingResults)
+		e := newEntry(c, timingResults)
+		if cfg.JSON {
+			logAt(statusLevel(e.Status), accesslog.RenderJSON(e))
+			return
+		}
+		logAt(statusLevel(e.Status), f.Render(e))
 	}
 }
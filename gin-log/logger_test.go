@@ -0,0 +1,113 @@
+package logm
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// logLine 是一行被捕获的日志及其等级
+type logLine struct {
+	level Level
+	line  string
+}
+
+// captureLogs 将 logDebug/logInfo/logWarning/logError 替换为记录到返回的切片中, 测试结束后恢复
+func captureLogs(t *testing.T) *[]logLine {
+	t.Helper()
+	var lines []logLine
+	origDebug, origInfo, origWarning, origError := logDebug, logInfo, logWarning, logError
+	record := func(level Level) func(string, ...interface{}) {
+		return func(format string, args ...interface{}) {
+			lines = append(lines, logLine{level, fmt.Sprintf(format, args...)})
+		}
+	}
+	logDebug, logInfo, logWarning, logError = record(LevelDebug), record(LevelInfo), record(LevelWarn), record(LevelError)
+	t.Cleanup(func() {
+		logDebug, logInfo, logWarning, logError = origDebug, origInfo, origWarning, origError
+	})
+	return &lines
+}
+
+// serve 使用 middlewares 与一个处理 path 的 handler 构造路由, 发送一个 GET 请求并返回响应
+func serve(path string, handler gin.HandlerFunc, middlewares ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	r := gin.New()
+	r.Use(middlewares...)
+	r.GET(path, handler)
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("User-Agent", "test-agent")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// onlyLine 返回唯一的一行日志, 日志数量不为 1 时测试失败
+func onlyLine(t *testing.T, lines []logLine) logLine {
+	t.Helper()
+	if len(lines) != 1 {
+		t.Fatalf("got %d log lines %q, want 1", len(lines), lines)
+	}
+	return lines[0]
+}
+
+// TestMiddlewareSkip 测试 SkipPaths 与 Skip 跳过的请求不记录日志, 且 Skip 在处理完请求后调用
+func TestMiddlewareSkip(t *testing.T) {
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	mw := MiddlewareWithConfig(Config{
+		Format:    "{path}",
+		SkipPaths: []string{"/healthz"},
+		Skip:      func(c *gin.Context) bool { return c.GetBool("quiet") },
+	})
+
+	lines := captureLogs(t)
+	serve("/healthz", ok, mw)
+	serve("/healthz/deep", ok, mw) // SkipPaths 是完全匹配
+	serve("/quiet", func(c *gin.Context) { c.Set("quiet", true) }, mw)
+	serve("/loud", ok, mw)
+	var got []string
+	for _, l := range *lines {
+		got = append(got, l.line)
+	}
+	if want := []string{"/healthz/deep", "/loud"}; !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+// TestMiddlewareStatusLevel 测试按响应状态码选择日志等级, 以及自定义的 StatusLevel
+func TestMiddlewareStatusLevel(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		want   Level
+	}{
+		{http.StatusOK, LevelInfo},
+		{http.StatusFound, LevelInfo},
+		{http.StatusNotFound, LevelWarn},
+		{http.StatusTooManyRequests, LevelWarn},
+		{http.StatusInternalServerError, LevelError},
+		{http.StatusBadGateway, LevelError},
+	} {
+		if got := DefaultStatusLevel(tc.status); got != tc.want {
+			t.Errorf("DefaultStatusLevel(%d) = %v, want %v", tc.status, got, tc.want)
+		}
+		lines := captureLogs(t)
+		serve("/", func(c *gin.Context) { c.Status(tc.status) }, Middleware())
+		if got := onlyLine(t, *lines).level; got != tc.want {
+			t.Errorf("status %d logged at %v, want %v", tc.status, got, tc.want)
+		}
+	}
+
+	lines := captureLogs(t)
+	debugAll := func(int) Level { return LevelDebug }
+	serve("/", func(c *gin.Context) { c.Status(http.StatusInternalServerError) }, MiddlewareWithConfig(Config{StatusLevel: debugAll}))
+	if got := onlyLine(t, *lines).level; got != LevelDebug {
+		t.Errorf("custom StatusLevel: logged at %v, want LevelDebug", got)
+	}
+}
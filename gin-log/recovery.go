@@ -0,0 +1,23 @@
+package logm
+
+import (
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery 返回一个从 panic 中恢复的中间件
+// panic 的值、请求路径、客户端 IP 与调用栈以 Error 等级写入 logger, 与访问日志位于同一日志流中, 然后以 500 响应
+// 应注册在访问日志中间件之后, 以便访问日志能记录到 500 状态码
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				logError("panic recovered: %v %s %s %s\n%s", err, c.ClientIP(), c.Request.Method, c.Request.URL.Path, debug.Stack())
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
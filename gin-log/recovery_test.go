@@ -0,0 +1,44 @@
+package logm
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestRecovery 测试 panic 被恢复后以 500 响应, 并以 Error 等级记录 panic 的值、路径与客户端 IP
+func TestRecovery(t *testing.T) {
+	lines := captureLogs(t)
+	w := serve("/boom", func(c *gin.Context) { panic("kaboom") }, MiddlewareWithFormat("{status} {path}"), Recovery())
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want 500", w.Code)
+	}
+
+	// 先是 Recovery 的错误日志, 然后是记录到 500 的访问日志
+	if len(*lines) != 2 {
+		t.Fatalf("got %d log lines %q, want 2", len(*lines), *lines)
+	}
+	panicLog, accessLog := (*lines)[0], (*lines)[1]
+	if panicLog.level != LevelError {
+		t.Errorf("panic logged at %v, want LevelError", panicLog.level)
+	}
+	for _, want := range []string{"panic recovered: kaboom", "192.0.2.1", "GET", "/boom", "recovery_test.go"} {
+		if !strings.Contains(panicLog.line, want) {
+			t.Errorf("panic log %q does not contain %q", panicLog.line, want)
+		}
+	}
+	if accessLog.line != "500 /boom" || accessLog.level != LevelError {
+		t.Errorf("access log = %+v, want \"500 /boom\" at LevelError", accessLog)
+	}
+
+	// 没有 panic 时不影响正常响应
+	lines = captureLogs(t)
+	if w := serve("/ok", func(c *gin.Context) { c.Status(http.StatusNoContent) }, Recovery()); w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want 204", w.Code)
+	}
+	if len(*lines) != 0 {
+		t.Errorf("unexpected logs %q", *lines)
+	}
+}
@@ -0,0 +1,59 @@
+package logm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// RequestIDHeader 是读取和回写请求 ID 的请求/响应头
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestIDKey 是请求 ID 在 gin.Context 中的键
+	RequestIDKey = "requestID"
+
+	// maxRequestIDLen 是接受的传入请求 ID 的最大长度
+	maxRequestIDLen = 128
+)
+
+// RequestID 返回一个为每个请求分配请求 ID 的中间件
+// 优先使用请求头 X-Request-ID 中的值, 缺失或不合法 (过长或包含空白、控制字符) 时生成一个随机 ID
+// ID 会写入 gin.Context 与响应头, 访问日志格式中可以通过 {requestID} 输出, 应注册在访问日志中间件之前
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID 返回 RequestID 中间件为该请求分配的 ID, 未分配时返回空字符串
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// validRequestID 判断传入的请求 ID 是否可以直接使用, 避免日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID 生成一个 32 位十六进制的随机请求 ID
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
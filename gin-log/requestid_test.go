@@ -0,0 +1,88 @@
+package logm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestValidRequestID(t *testing.T) {
+	for _, tc := range []struct {
+		id   string
+		want bool
+	}{
+		{"abc-123", true},
+		{"req_01H8XYZ", true},
+		{strings.Repeat("a", maxRequestIDLen), true},
+		{"", false},
+		{strings.Repeat("a", maxRequestIDLen+1), false},
+		{"has space", false},
+		{"tab\tid", false},
+		{"line\nbreak", false},
+		{"nul\x00", false},
+		{"del\x7f", false},
+	} {
+		if got := validRequestID(tc.id); got != tc.want {
+			t.Errorf("validRequestID(%q) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+// TestRequestID 测试使用或生成请求 ID, 写入响应头并在访问日志中输出
+func TestRequestID(t *testing.T) {
+	hexID := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	r := gin.New()
+	r.Use(RequestID(), MiddlewareWithFormat("{requestID}"))
+	r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, GetRequestID(c)) })
+	do := func(header string) (*httptest.ResponseRecorder, string) {
+		lines := captureLogs(t)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(RequestIDHeader, header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w, onlyLine(t, *lines).line
+	}
+
+	// 合法的传入 ID 原样使用
+	w, logged := do("client-id-1")
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-1" {
+		t.Errorf("response header = %q, want client-id-1", got)
+	}
+	if w.Body.String() != "client-id-1" || logged != "client-id-1" {
+		t.Errorf("GetRequestID = %q, logged %q, want client-id-1", w.Body.String(), logged)
+	}
+
+	// 缺失或不合法时生成新的随机 ID
+	seen := map[string]bool{}
+	for _, header := range []string{"", "bad id", strings.Repeat("x", maxRequestIDLen+1)} {
+		w, logged := do(header)
+		id := w.Header().Get(RequestIDHeader)
+		if !hexID.MatchString(id) {
+			t.Errorf("header %q: generated ID %q is not 32 hex digits", header, id)
+		}
+		if w.Body.String() != id || logged != id {
+			t.Errorf("header %q: GetRequestID = %q, logged %q, want %q", header, w.Body.String(), logged, id)
+		}
+		if seen[id] {
+			t.Errorf("generated ID %q twice", id)
+		}
+		seen[id] = true
+	}
+
+	// 未使用 RequestID 中间件时为空
+	lines := captureLogs(t)
+	serve("/", func(c *gin.Context) {
+		if id := GetRequestID(c); id != "" {
+			t.Errorf("GetRequestID without middleware = %q", id)
+		}
+	}, MiddlewareWithFormat("[{requestID}]"))
+	if got := onlyLine(t, *lines).line; got != "[]" {
+		t.Errorf("logged %q, want []", got)
+	}
+}
@@ -0,0 +1,41 @@
+package logm
+
+import (
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/accesslog"
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+// DefaultFormat 是 Middleware 使用的默认日志格式, 与 gin-log 的字段布局一致
+const DefaultFormat = accesslog.DefaultFormat
+
+// RequestIDHeader 是读取请求 ID 的响应头, 与 hertz-contrib/requestid 默认使用的响应头一致
+const RequestIDHeader = "X-Request-ID"
+
+// newEntry 从 RequestContext 中取出渲染一行访问日志所需的数据
+func newEntry(c *app.RequestContext, latency time.Duration) *accesslog.Entry {
+	return &accesslog.Entry{
+		ClientIP:  c.ClientIP(),
+		Method:    string(c.Method()),
+		Protocol:  c.Request.Header.GetProtocol(),
+		Path:      string(c.Path()),
+		UserAgent: string(c.Request.Header.UserAgent()),
+		Status:    c.Response.StatusCode(),
+		Bytes:     responseSize(c),
+		RequestID: string(c.Response.Header.Peek(RequestIDHeader)),
+		Latency:   latency,
+	}
+}
+
+// responseSize 返回响应体的字节数
+// 流式响应体只能使用 Content-Length, 未知时 (例如被 hwriter 接管的 chunked 响应) 记为 0
+func responseSize(c *app.RequestContext) int {
+	if !c.Response.IsBodyStream() {
+		return len(c.Response.BodyBytes())
+	}
+	if size := c.Response.Header.ContentLength(); size > 0 {
+		return size
+	}
+	return 0
+}
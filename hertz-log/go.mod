@@ -3,6 +3,7 @@ module github.com/WJQSERVER-STUDIO/go-utils/hertz-log
 go 1.24.3
 
 require (
+	github.com/WJQSERVER-STUDIO/go-utils/accesslog v0.1.0
 	github.com/WJQSERVER-STUDIO/logger v1.6.0
 	github.com/cloudwego/hertz v0.9.7
 )
@@ -13,6 +14,7 @@ require (
 	github.com/bytedance/sonic v1.13.2 // indirect
 	github.com/bytedance/sonic/loader v0.2.4 // indirect
 	github.com/cloudwego/base64x v0.1.5 // indirect
+	github.com/cloudwego/netpoll v0.6.4 // indirect
 	github.com/fsnotify/fsnotify v1.9.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
 	github.com/nyaruka/phonenumbers v1.6.1 // indirect
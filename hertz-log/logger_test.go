@@ -0,0 +1,204 @@
+package logm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/config"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/cloudwego/hertz/pkg/route"
+)
+
+// logLine 是一行被捕获的日志及其等级
+type logLine struct {
+	level Level
+	line  string
+}
+
+// captureLogs 将 logDebug/logInfo/logWarning/logError 替换为记录到返回的切片中, 测试结束后恢复
+func captureLogs(t *testing.T) *[]logLine {
+	t.Helper()
+	var lines []logLine
+	origDebug, origInfo, origWarning, origError := logDebug, logInfo, logWarning, logError
+	record := func(level Level) func(string, ...interface{}) {
+		return func(format string, args ...interface{}) {
+			lines = append(lines, logLine{level, fmt.Sprintf(format, args...)})
+		}
+	}
+	logDebug, logInfo, logWarning, logError = record(LevelDebug), record(LevelInfo), record(LevelWarn), record(LevelError)
+	t.Cleanup(func() {
+		logDebug, logInfo, logWarning, logError = origDebug, origInfo, origWarning, origError
+	})
+	return &lines
+}
+
+// serve 使用 mw 与一个处理 path 的 handler 构造路由, 发送一个 GET 请求并返回响应
+func serve(path string, handler app.HandlerFunc, mw app.HandlerFunc) *ut.ResponseRecorder {
+	e := route.NewEngine(config.NewOptions(nil))
+	e.Use(mw)
+	e.GET(path, handler)
+	return ut.PerformRequest(e, http.MethodGet, path, nil, ut.Header{Key: "User-Agent", Value: "test-agent"})
+}
+
+// onlyLine 返回唯一的一行日志, 日志数量不为 1 时测试失败
+func onlyLine(t *testing.T, lines []logLine) logLine {
+	t.Helper()
+	if len(lines) != 1 {
+		t.Fatalf("got %d log lines %q, want 1", len(lines), lines)
+	}
+	return lines[0]
+}
+
+func hello(ctx context.Context, c *app.RequestContext) { c.String(http.StatusOK, "hello") }
+
+// TestMiddlewareWithFormat 测试占位符替换, 以及未知占位符与未闭合的 { 按原样输出
+func TestMiddlewareWithFormat(t *testing.T) {
+	for _, tc := range []struct {
+		format, want string
+	}{
+		{"{method} {path} {userAgent} {status} {bytes}", "GET /items test-agent 200 5"},
+		{"{method} {unknown} {path}", "GET {unknown} /items"},
+		{"{method} {path", "GET {path"},
+		{"[{requestID}]", "[]"}, // 没有 X-Request-ID 响应头
+	} {
+		lines := captureLogs(t)
+		serve("/items", hello, MiddlewareWithFormat(tc.format))
+		if got := onlyLine(t, *lines).line; got != tc.want {
+			t.Errorf("format %q: got %q, want %q", tc.format, got, tc.want)
+		}
+	}
+
+	// 空格式使用 DefaultFormat
+	lines := captureLogs(t)
+	serve("/items", hello, MiddlewareWithConfig(Config{}))
+	if got := onlyLine(t, *lines).line; !strings.Contains(got, " GET ") || !strings.Contains(got, " /items test-agent 200 5 ") {
+		t.Errorf("default format: got %q", got)
+	}
+}
+
+// TestMiddlewareSkip 测试 SkipPaths 与 Skip 跳过的请求不记录日志, 且 Skip 在处理完请求后调用
+func TestMiddlewareSkip(t *testing.T) {
+	ok := func(ctx context.Context, c *app.RequestContext) { c.Status(http.StatusOK) }
+	mw := MiddlewareWithConfig(Config{
+		Format:    "{path}",
+		SkipPaths: []string{"/healthz"},
+		Skip:      func(c *app.RequestContext) bool { return c.GetBool("quiet") },
+	})
+
+	lines := captureLogs(t)
+	serve("/healthz", ok, mw)
+	serve("/healthz/deep", ok, mw) // SkipPaths 是完全匹配
+	serve("/quiet", func(ctx context.Context, c *app.RequestContext) { c.Set("quiet", true) }, mw)
+	serve("/loud", ok, mw)
+	var got []string
+	for _, l := range *lines {
+		got = append(got, l.line)
+	}
+	if want := []string{"/healthz/deep", "/loud"}; !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+// TestMiddlewareStatusLevel 测试按响应状态码选择日志等级, 以及自定义的 StatusLevel
+func TestMiddlewareStatusLevel(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		want   Level
+	}{
+		{http.StatusOK, LevelInfo},
+		{http.StatusFound, LevelInfo},
+		{http.StatusNotFound, LevelWarn},
+		{http.StatusInternalServerError, LevelError},
+	} {
+		lines := captureLogs(t)
+		serve("/", func(ctx context.Context, c *app.RequestContext) { c.Status(tc.status) }, Middleware())
+		if got := onlyLine(t, *lines).level; got != tc.want {
+			t.Errorf("status %d logged at %v, want %v", tc.status, got, tc.want)
+		}
+	}
+
+	lines := captureLogs(t)
+	debugAll := func(int) Level { return LevelDebug }
+	serve("/", func(ctx context.Context, c *app.RequestContext) { c.Status(http.StatusInternalServerError) }, MiddlewareWithConfig(Config{StatusLevel: debugAll}))
+	if got := onlyLine(t, *lines).level; got != LevelDebug {
+		t.Errorf("custom StatusLevel: logged at %v, want LevelDebug", got)
+	}
+}
+
+// TestMiddlewareJSON 测试 JSON 模式的字段名与取值
+func TestMiddlewareJSON(t *testing.T) {
+	lines := captureLogs(t)
+	serve("/items", hello, MiddlewareWithConfig(Config{JSON: true, Format: "{path}"}))
+	var got map[string]any
+	if err := json.Unmarshal([]byte(onlyLine(t, *lines).line), &got); err != nil {
+		t.Fatal(err)
+	}
+	if keys := slices.Sorted(maps.Keys(got)); !slices.Equal(keys, []string{"bytes", "clientIP", "latencyMs", "method", "path", "status", "userAgent"}) {
+		t.Errorf("keys = %v", keys)
+	}
+	for k, v := range map[string]any{
+		"method":    "GET",
+		"path":      "/items",
+		"status":    float64(http.StatusOK),
+		"userAgent": "test-agent",
+		"bytes":     float64(5),
+	} {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+// TestRequestID 测试 {requestID} 与 JSON 的 requestID 字段取自响应头 X-Request-ID
+func TestRequestID(t *testing.T) {
+	withID := func(ctx context.Context, c *app.RequestContext) {
+		c.Header(RequestIDHeader, "req-1")
+		c.String(http.StatusOK, "hello")
+	}
+	lines := captureLogs(t)
+	serve("/items", withID, MiddlewareWithFormat("{requestID} {path}"))
+	if got := onlyLine(t, *lines).line; got != "req-1 /items" {
+		t.Errorf("got %q, want %q", got, "req-1 /items")
+	}
+
+	lines = captureLogs(t)
+	serve("/items", withID, MiddlewareWithConfig(Config{JSON: true}))
+	var got map[string]any
+	if err := json.Unmarshal([]byte(onlyLine(t, *lines).line), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["requestID"] != "req-1" {
+		t.Errorf("requestID = %v, want req-1", got["requestID"])
+	}
+}
+
+// TestResponseSize 测试 {bytes} 对普通响应体与流式响应体的统计
+func TestResponseSize(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		handler app.HandlerFunc
+		want    string
+	}{
+		{"body", hello, "5"},
+		{"no body", func(ctx context.Context, c *app.RequestContext) { c.Status(http.StatusNoContent) }, "0"},
+		{"stream with length", func(ctx context.Context, c *app.RequestContext) {
+			c.SetBodyStream(strings.NewReader("hello world"), 11)
+		}, "11"},
+		{"stream without length", func(ctx context.Context, c *app.RequestContext) {
+			c.SetBodyStream(strings.NewReader("hello world"), -1)
+		}, "0"},
+	} {
+		lines := captureLogs(t)
+		serve("/", tc.handler, MiddlewareWithFormat("{bytes}"))
+		if got := onlyLine(t, *lines).line; got != tc.want {
+			t.Errorf("%s: {bytes} = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
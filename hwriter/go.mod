@@ -1,10 +1,12 @@
 module github.com/WJQSERVER-STUDIO/go-utils/hwriter
 
-go 1.24.1
+go 1.24.3
 
 require (
+	github.com/WJQSERVER-STUDIO/go-utils/limitreader v0.1.0
 	github.com/cloudwego/hertz v0.9.6
 	github.com/valyala/bytebufferpool v1.0.0
+	golang.org/x/time v0.11.0
 )
 
 require (
@@ -12,6 +14,7 @@ require (
 	github.com/bytedance/sonic v1.13.1 // indirect
 	github.com/bytedance/sonic/loader v0.2.4 // indirect
 	github.com/cloudwego/base64x v0.1.5 // indirect
+	github.com/cloudwego/netpoll v0.6.4 // indirect
 	github.com/fsnotify/fsnotify v1.8.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.10 // indirect
 	github.com/nyaruka/phonenumbers v1.5.0 // indirect
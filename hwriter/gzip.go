@@ -0,0 +1,31 @@
+package hwriter
+
+import (
+	"compress/gzip"
+	"strings"
+	"sync"
+)
+
+// gzipWriterPool 复用 gzip.Writer, 避免每个响应都重新分配压缩状态
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
+// acceptsGzip 判断 Accept-Encoding 请求头是否接受 gzip, 显式声明 q=0 的视为不接受
+func acceptsGzip(acceptEncoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.TrimSpace(coding)
+		if !strings.EqualFold(coding, "gzip") && coding != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		if q == "q=0" || strings.HasPrefix(q, "q=0.") && strings.Trim(q[4:], "0") == "" {
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package hwriter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/cloudwego/hertz/pkg/common/ut"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	for _, tc := range []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip;q=0.8", true},
+		{"br, deflate", false},
+		{"*", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.000", false},
+		{"gzip;q=0.001", true},
+		{"gzip;q=1", true},
+		{"gzips", false},
+	} {
+		if got := acceptsGzip(tc.header); got != tc.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tc.header, got, tc.want)
+		}
+	}
+}
+
+// TestWriterCompress 测试按 Accept-Encoding 协商 gzip 压缩, 以及响应已设置 Content-Encoding 时不再压缩
+func TestWriterCompress(t *testing.T) {
+	data := bytes.Repeat(testBody(1000), 50)
+	for _, tc := range []struct {
+		name           string
+		acceptEncoding string
+		opts           Options
+		wantGzip       bool
+	}{
+		{"flush each", "gzip, deflate", Options{Size: 4096, Compress: true}, true},
+		{"flush bytes", "gzip", Options{Size: 8192, Compress: true, FlushEveryBytes: 16384}, true},
+		{"not accepted", "br", Options{Compress: true}, false},
+		{"disabled", "gzip", Options{}, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, conn := newTestContext(ut.Header{Key: "Accept-Encoding", Value: tc.acceptEncoding})
+			written, _, err := WriterStats(t.Context(), &closeRecorder{Reader: bytes.NewReader(data)}, c, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != int64(len(data)) {
+				t.Errorf("written = %d, want the uncompressed size %d", written, len(data))
+			}
+
+			resp, body, _ := readResponse(t, c, conn)
+			if !tc.wantGzip {
+				if ce := resp.Header.Get("Content-Encoding"); ce != "" {
+					t.Errorf("Content-Encoding = %q, want none", ce)
+				}
+				if !bytes.Equal(body, data) {
+					t.Fatalf("body mismatch (got %d bytes)", len(body))
+				}
+				return
+			}
+			if ce := resp.Header.Get("Content-Encoding"); ce != "gzip" {
+				t.Errorf("Content-Encoding = %q, want gzip", ce)
+			}
+			if v := resp.Header.Get("Vary"); v != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", v)
+			}
+			if len(body) >= len(data) {
+				t.Errorf("compressed body is %d bytes, not smaller than %d", len(body), len(data))
+			}
+			zr, err := gzip.NewReader(bytes.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			plain, err := io.ReadAll(zr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(plain, data) {
+				t.Fatalf("decompressed body mismatch (got %d bytes)", len(plain))
+			}
+		})
+	}
+
+	// 已经编码的响应体原样写出
+	c, conn := newTestContext(ut.Header{Key: "Accept-Encoding", Value: "gzip"})
+	c.Response.Header.Set("Content-Encoding", "br")
+	if err := WriterWithOptions(&closeRecorder{Reader: bytes.NewReader(data)}, c, Options{Compress: true}); err != nil {
+		t.Fatal(err)
+	}
+	resp, body, _ := readResponse(t, c, conn)
+	if ce := resp.Header.Get("Content-Encoding"); ce != "br" {
+		t.Errorf("Content-Encoding = %q, want br", ce)
+	}
+	if !bytes.Equal(body, data) {
+		t.Fatalf("pre-encoded body mismatch (got %d bytes)", len(body))
+	}
+}
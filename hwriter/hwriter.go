@@ -1,23 +1,183 @@
 package hwriter
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
+	"github.com/WJQSERVER-STUDIO/go-utils/limitreader"
 	"github.com/cloudwego/hertz/pkg/app"
 	hresp "github.com/cloudwego/hertz/pkg/protocol/http1/resp"
 	"github.com/valyala/bytebufferpool"
+	"golang.org/x/time/rate"
 )
 
+// defaultBufSize 是 Writer 使用的默认拷贝缓冲区大小
+const defaultBufSize = 32768 // 32KB
+
+// Writer 以 chunked 方式将 resp 的内容流式写入响应, 使用 32KB 的拷贝缓冲区, 结束后关闭 resp
 func Writer(resp io.ReadCloser, c *app.RequestContext) error {
-	defer resp.Close()
+	return WriterWithSize(resp, c, defaultBufSize)
+}
+
+// WriterWithSize 与 Writer 相同, 但使用 size 字节的拷贝缓冲区
+// 大文件/媒体流可以使用更大的缓冲区减少系统调用, SSE 等低延迟场景可以使用更小的缓冲区
+// size 不是正数时使用默认的 32KB
+func WriterWithSize(resp io.ReadCloser, c *app.RequestContext, size int) error {
+	return WriterWithOptions(resp, c, Options{Size: size})
+}
+
+// Options 是 WriterWithOptions 的配置
+type Options struct {
+	// Size 是拷贝缓冲区大小, 不是正数时使用默认的 32KB
+	Size int
+
+	// FlushEveryBytes 大于 0 时, 自上次刷新以来累计写入这么多字节后才刷新一次
+	FlushEveryBytes int
+
+	// FlushInterval 大于 0 时, 距上次刷新超过该时间后刷新一次
+	// 只在写入时检查, 不会启动额外的定时器
+	FlushInterval time.Duration
+
+	// RateLimit 大于 0 时, 使用 limitreader 按该速率 (Bytes/s) 读取 resp, 同时受 limitreader 全局限速的约束
+	// 设置为 rate.Inf 时只应用全局限速; 为 0 时不做任何限速
+	RateLimit rate.Limit
+
+	// RateBurst 是限速令牌桶的突发容量, <= 0 时默认为每秒速率对应的字节数
+	RateBurst int
+
+	// Compress 为 true 且客户端的 Accept-Encoding 接受 gzip 时, 对响应体进行 gzip 压缩并设置 Content-Encoding: gzip
+	// 每次刷新都会先刷新 gzip 的缓冲数据, 结束时关闭 gzip 写入器; 客户端不支持或响应已设置 Content-Encoding 时不压缩
+	// 压缩时 written 与 Progress 统计的是压缩前的字节数
+	Compress bool
+
+	// SmallBodyThreshold 大于 0 且响应体长度已知、不超过该值时, 一次性读取整个响应体,
+	// 以带 Content-Length 的普通响应写出, 不使用 chunked 编码, 也不进行压缩
+	// 如果实际读到的数据超过阈值, 会退回 chunked 方式继续写出
+	SmallBodyThreshold int
+
+	// ContentLength 大于 0 时表示响应体的已知长度 (例如上游的 Content-Length), 用于 SmallBodyThreshold 的判断
+	// 未设置时, 如果 resp 实现了 Len() int (如 *bytes.Reader、*strings.Reader), 使用其返回值
+	ContentLength int64
+
+	// Progress 不为 nil 时, 每次刷新后以累计写入的字节数调用, 返回前 (无论成功与否) 再调用一次
+	// 它在拷贝所在的 goroutine 中同步调用, 不持有任何锁, 耗时较长只会减慢拷贝
+	Progress func(written int64)
+}
+
+// WriterWithOptions 与 Writer 相同, 但可以通过 opts 配置缓冲区大小与刷新策略
+// FlushEveryBytes 与 FlushInterval 都为 0 时, 每写入一块就立即刷新, 与 Writer 的行为一致, 适合 SSE 等流式场景
+// 设置任意一个时, 满足其中一个条件才刷新, 可以显著提升大文件下载的吞吐量; 无论哪种方式, 结束时都会刷新最后的数据
+func WriterWithOptions(resp io.ReadCloser, c *app.RequestContext, opts Options) error {
+	return WriterContext(context.Background(), resp, c, opts)
+}
+
+// WriterContext 与 WriterWithOptions 相同, 但在 ctx 取消 (例如下游客户端断开) 时立即停止拷贝
+// 取消时会关闭 resp 以打断阻塞中的读取, 避免继续从上游拉取数据, 并直接返回 ctx.Err() (例如 context.Canceled)
+// 通常传入请求处理函数收到的 ctx
+// 如果 resp 是 *limitreader.RateLimitedReader, 它的 Context 会被替换为 ctx, 此时 opts.RateLimit 不再生效
+func WriterContext(ctx context.Context, resp io.ReadCloser, c *app.RequestContext, opts Options) error {
+	_, err := writeStream(ctx, resp, c, opts)
+	return err
+}
+
+// WriterStats 与 WriterContext 相同, 但额外返回写入响应的字节数与耗时, 便于记录访问日志
+// 出错时 written 为出错前已写入的字节数
+func WriterStats(ctx context.Context, resp io.ReadCloser, c *app.RequestContext, opts Options) (written int64, elapsed time.Duration, err error) {
+	start := time.Now()
+	written, err = writeStream(ctx, resp, c, opts)
+	return written, time.Since(start), err
+}
+
+// writeStream 是 Writer 系列函数的核心实现, 返回写入的字节数
+func writeStream(ctx context.Context, resp io.ReadCloser, c *app.RequestContext, opts Options) (written int64, err error) {
+	if opts.Progress != nil {
+		defer func() { opts.Progress(written) }()
+	}
+
+	// 在包装 resp 之前确定响应体长度, 包装后 Len 将不可用
+	bodyLen, knownLen := opts.ContentLength, opts.ContentLength > 0
+	if l, ok := resp.(interface{ Len() int }); ok && !knownLen {
+		bodyLen, knownLen = int64(l.Len()), true
+	}
+
+	// 限速读取器在 ctx 取消时也会停止等待
+	if rlr, ok := resp.(*limitreader.RateLimitedReader); ok {
+		rlr.SetContext(ctx)
+	} else if opts.RateLimit > 0 {
+		resp = limitreader.NewRateLimitedReader(resp, opts.RateLimit, opts.RateBurst, ctx)
+	}
+
+	// ctx 取消时由 AfterFunc 关闭 resp, 否则在返回时关闭, 保证只关闭一次
+	stop := context.AfterFunc(ctx, func() { resp.Close() })
+	defer func() {
+		if stop() {
+			resp.Close()
+		}
+	}()
+
+	// src 是实际读取的来源, resp 只用于关闭
+	var src io.Reader = resp
+
+	// 已知长度的小响应体一次性读取后以 Content-Length 方式写出
+	if opts.SmallBodyThreshold > 0 && knownLen && bodyLen <= int64(opts.SmallBodyThreshold) {
+		bb := bytebufferpool.Get()
+		defer bytebufferpool.Put(bb)
+		// 多读取一个字节, 用于发现长度与实际不符的响应体
+		if _, err := bb.ReadFrom(io.LimitReader(resp, int64(opts.SmallBodyThreshold)+1)); err != nil {
+			if cerr := ctx.Err(); cerr != nil {
+				return 0, cerr
+			}
+			return 0, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if len(bb.B) <= opts.SmallBodyThreshold {
+			c.Response.SetBody(bb.B) // SetBody 会拷贝数据, bb 可以安全归还
+			return int64(len(bb.B)), nil
+		}
+		// 实际数据超过阈值, 已读取的部分作为前缀继续以 chunked 方式写出
+		src = io.MultiReader(bytes.NewReader(bb.B), resp)
+	}
+
+	size := opts.Size
+	if size <= 0 {
+		size = defaultBufSize
+	}
+
+	// 未设置任何阈值时每块都刷新
+	flushEach := opts.FlushEveryBytes <= 0 && opts.FlushInterval <= 0
+
+	// 底层连接 (netpoll) 写入较大的数据块时不会拷贝, 只保留引用直到 Flush,
+	// 按阈值刷新时 buf 会在刷新前被下一次读取覆盖, 因此先拷贝到 pending 中, 刷新后再复用
+	var out io.Writer = c
+	var pending *pendingWriter
+	if !flushEach {
+		pending = &pendingWriter{w: c, buf: bytebufferpool.Get()}
+		defer bytebufferpool.Put(pending.buf)
+		out = pending
+	}
+
+	// w 是响应体的写入目标, 压缩时为包装了响应的 gzip.Writer
+	var w io.Writer = out
+	var gz *gzip.Writer
+	if opts.Compress && len(c.Response.Header.Peek("Content-Encoding")) == 0 &&
+		acceptsGzip(string(c.Request.Header.Peek("Accept-Encoding"))) {
+		c.Response.Header.Set("Content-Encoding", "gzip")
+		c.Response.Header.Set("Vary", "Accept-Encoding")
+		c.Response.Header.Del("Content-Length") // 压缩后长度未知
+		gz = gzipWriterPool.Get().(*gzip.Writer)
+		gz.Reset(out)
+		defer gzipWriterPool.Put(gz)
+		w = gz
+	}
 
 	c.Response.HijackWriter(hresp.NewChunkedBodyWriter(&c.Response, c.GetWriter()))
 
 	bufWrapper := bytebufferpool.Get()
 	buf := bufWrapper.B
-	size := 32768 // 32KB
 	buf = buf[:cap(buf)]
 	if len(buf) < size {
 		buf = append(buf, make([]byte, size-len(buf))...)
@@ -25,35 +185,81 @@ func Writer(resp io.ReadCloser, c *app.RequestContext) error {
 	buf = buf[:size] // 将缓冲区限制为 'size'
 	defer bytebufferpool.Put(bufWrapper)
 
+	unflushed := 0 // 自上次刷新以来写入的字节数
+	lastFlush := time.Now()
+
 	for {
-		n, err := resp.Read(buf)
-		if err != nil {
-			if err == io.EOF {
-				_, err := c.Write(buf[:n])
-				if err != nil {
-					return fmt.Errorf("failed to write chunk: %w", err)
-				}
-				c.Flush() // Flush the last chunk
-				break     // 读取到文件末尾
-			}
-			return fmt.Errorf("failed to read response body: %w", err)
+		if err := ctx.Err(); err != nil {
+			return written, err
 		}
-
+		n, err := src.Read(buf)
 		if n > 0 { // Only write if we actually read something
-			_, err = c.Write(buf[:n])
-			if err != nil {
+			if _, werr := w.Write(buf[:n]); werr != nil {
 				// Handle write error (consider logging and potentially aborting)
-				return fmt.Errorf("failed to write chunk: %w", err)
+				return written, fmt.Errorf("failed to write chunk: %w", werr)
 			}
+			unflushed += n
+			written += int64(n)
 
-			//Consider removing Flush in most case.  Only keep it if you *really* need it.
-			if err := c.Flush(); err != nil {
-				// More robust error handling for Flush()
-				c.AbortWithStatus(http.StatusInternalServerError) // Abort the response
-				return fmt.Errorf("failed to flush chunk: %w", err)
+			if flushEach ||
+				(opts.FlushEveryBytes > 0 && unflushed >= opts.FlushEveryBytes) ||
+				(opts.FlushInterval > 0 && time.Since(lastFlush) >= opts.FlushInterval) {
+				if gz != nil {
+					if ferr := gz.Flush(); ferr != nil {
+						return written, fmt.Errorf("failed to flush gzip writer: %w", ferr)
+					}
+				}
+				if ferr := c.Flush(); ferr != nil {
+					// More robust error handling for Flush()
+					c.AbortWithStatus(http.StatusInternalServerError) // Abort the response
+					return written, fmt.Errorf("failed to flush chunk: %w", ferr)
+				}
+				pending.reset()
+				unflushed = 0
+				lastFlush = time.Now()
+				if opts.Progress != nil {
+					opts.Progress(written)
+				}
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				break // 读取到文件末尾
+			}
+			if cerr := ctx.Err(); cerr != nil {
+				return written, cerr // resp 因 ctx 取消被关闭
 			}
+			return written, fmt.Errorf("failed to read response body: %w", err)
+		}
+	}
+
+	if gz != nil {
+		// 关闭 gzip 写入器, 写出剩余的压缩数据和 gzip 尾部
+		if err := gz.Close(); err != nil {
+			return written, fmt.Errorf("failed to close gzip writer: %w", err)
 		}
 	}
+	c.Flush() // Flush the last chunk
+	pending.reset()
+
+	return written, nil
+}
+
+// pendingWriter 将写入的数据拷贝到 buf 后再写入 w, 保证刷新前 w 引用的数据不会被调用方复用的缓冲区覆盖
+type pendingWriter struct {
+	w   io.Writer
+	buf *bytebufferpool.ByteBuffer
+}
+
+func (p *pendingWriter) Write(b []byte) (int, error) {
+	start := len(p.buf.B)
+	p.buf.B = append(p.buf.B, b...)
+	return p.w.Write(p.buf.B[start:])
+}
 
-	return nil
+// reset 在数据刷新后释放已拷贝的数据, p 为 nil 时什么都不做
+func (p *pendingWriter) reset() {
+	if p != nil {
+		p.buf.Reset()
+	}
 }
@@ -0,0 +1,378 @@
+package hwriter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/limitreader"
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/common/test/mock"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"golang.org/x/time/rate"
+)
+
+// testConn 是统计 Flush 次数的 mock 连接
+type testConn struct {
+	*mock.Conn
+	flushes int
+}
+
+func (c *testConn) Flush() error {
+	c.flushes++
+	return c.Conn.Flush()
+}
+
+// newTestContext 创建一个连接到 mock 连接的 RequestContext, 写入连接的数据可以用 readResponse 读回
+func newTestContext(headers ...ut.Header) (*app.RequestContext, *testConn) {
+	c := ut.CreateUtRequestContext(http.MethodGet, "/download", nil, headers...)
+	conn := &testConn{Conn: mock.NewConn("")}
+	c.SetConn(conn)
+	return c, conn
+}
+
+// readResponse 模拟 hertz 在处理函数返回后结束 chunked 响应, 解析连接上写出的原始响应,
+// 返回响应、解码 chunked 后的响应体以及每个 chunk 的长度
+func readResponse(t *testing.T, c *app.RequestContext, conn *testConn) (*http.Response, []byte, []int) {
+	t.Helper()
+	w := c.Response.GetHijackWriter()
+	if w == nil {
+		t.Fatal("response was not written with a chunked writer")
+	}
+	if err := w.Finalize(); err != nil {
+		t.Fatalf("Finalize: %v", err)
+	}
+	if err := conn.Conn.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	rec := conn.WriterRecorder()
+	raw, err := rec.ReadBinary(rec.WroteLen())
+	if err != nil {
+		t.Fatalf("read written bytes: %v", err)
+	}
+	raw = bytes.Clone(raw)
+
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(raw)), nil)
+	if err != nil {
+		t.Fatalf("parse response %q: %v", raw, err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	return resp, body, chunkSizes(t, raw)
+}
+
+// chunkSizes 返回原始 chunked 响应中每个非结尾 chunk 的长度
+func chunkSizes(t *testing.T, raw []byte) []int {
+	t.Helper()
+	_, rest, ok := bytes.Cut(raw, []byte("\r\n\r\n"))
+	if !ok {
+		t.Fatalf("no header terminator in %q", raw)
+	}
+	var sizes []int
+	for {
+		line, after, ok := bytes.Cut(rest, []byte("\r\n"))
+		if !ok {
+			t.Fatalf("truncated chunked body %q", rest)
+		}
+		n, err := strconv.ParseInt(string(line), 16, 64)
+		if err != nil {
+			t.Fatalf("bad chunk size %q: %v", line, err)
+		}
+		if n == 0 {
+			return sizes
+		}
+		sizes = append(sizes, int(n))
+		rest = after[n+2:] // 跳过数据与其后的 CRLF
+	}
+}
+
+// testBody 返回 n 字节各不相同的测试数据, 便于发现数据错位
+func testBody(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+// TestWriterWithSize 测试响应以 chunked 方式写出, 每个 chunk 不超过缓冲区大小, 结束后关闭 resp
+func TestWriterWithSize(t *testing.T) {
+	data := testBody(10000)
+	for _, tc := range []struct {
+		size, wantChunk int
+	}{
+		{1024, 1024},
+		{0, defaultBufSize}, // 非正数使用默认大小
+	} {
+		c, conn := newTestContext()
+		src := &closeRecorder{Reader: bytes.NewReader(data)}
+		if err := WriterWithSize(src, c, tc.size); err != nil {
+			t.Fatalf("size %d: %v", tc.size, err)
+		}
+		if !src.closed {
+			t.Errorf("size %d: resp was not closed", tc.size)
+		}
+
+		resp, body, chunks := readResponse(t, c, conn)
+		if len(resp.TransferEncoding) != 1 || resp.TransferEncoding[0] != "chunked" {
+			t.Errorf("size %d: Transfer-Encoding = %v, want chunked", tc.size, resp.TransferEncoding)
+		}
+		if !bytes.Equal(body, data) {
+			t.Fatalf("size %d: body mismatch (got %d bytes)", tc.size, len(body))
+		}
+		for _, n := range chunks {
+			if n > tc.wantChunk {
+				t.Errorf("size %d: chunk of %d bytes exceeds the buffer", tc.size, n)
+			}
+		}
+	}
+}
+
+// closeRecorder 记录 Close 是否被调用, 且不暴露 Len 以走流式写出的路径
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+// TestWriterWithOptionsFlush 测试按字节数与时间间隔刷新时的刷新次数, 以及刷新前缓冲区被复用时数据不会错乱
+// 块大小超过 4KB, netpoll 写入时不会拷贝, 可以发现刷新前复用缓冲区的问题
+func TestWriterWithOptionsFlush(t *testing.T) {
+	const size = 8192
+	data := testBody(10 * size)
+	for _, tc := range []struct {
+		name        string
+		opts        Options
+		wantFlushes int
+	}{
+		{"each", Options{Size: size}, 11}, // 每块一次, 结束时一次
+		{"bytes", Options{Size: size, FlushEveryBytes: 4 * size}, 3},
+		{"interval", Options{Size: size, FlushInterval: time.Hour}, 1},
+		{"interval elapsed", Options{Size: size, FlushInterval: time.Nanosecond}, 11},
+		{"bytes or interval", Options{Size: size, FlushEveryBytes: 4 * size, FlushInterval: time.Hour}, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, conn := newTestContext()
+			if err := WriterWithOptions(&closeRecorder{Reader: bytes.NewReader(data)}, c, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			if conn.flushes != tc.wantFlushes {
+				t.Errorf("flushes = %d, want %d", conn.flushes, tc.wantFlushes)
+			}
+			_, body, _ := readResponse(t, c, conn)
+			if !bytes.Equal(body, data) {
+				t.Fatalf("body mismatch (got %d bytes)", len(body))
+			}
+		})
+	}
+}
+
+// TestWriterContextCancel 测试 ctx 取消时关闭 resp 打断阻塞的读取并返回 ctx.Err()
+func TestWriterContextCancel(t *testing.T) {
+	c, conn := newTestContext()
+	pr, pw := io.Pipe()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		pw.Write([]byte("hello")) // 读取方取走数据后上游不再发送, 下一次读取一直阻塞
+		cancel()
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- WriterContext(ctx, pr, c, Options{}) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WriterContext did not return after cancel")
+	}
+	if _, err := pw.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("resp was not closed: write got %v", err)
+	}
+	if _, body, _ := readResponse(t, c, conn); string(body) != "hello" {
+		t.Errorf("body = %q, want %q", body, "hello")
+	}
+
+	// 已取消的 ctx 不读取任何数据, resp 同样会被关闭
+	c, _ = newTestContext()
+	pr, pw = io.Pipe()
+	if err := WriterContext(ctx, pr, c, Options{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled ctx: got %v, want context.Canceled", err)
+	}
+	// 没有读取方时写入会阻塞到 resp 被关闭
+	if _, err := pw.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("canceled ctx: resp was not closed: write got %v", err)
+	}
+}
+
+// TestWriterRateLimit 测试 RateLimit 限制写出速度, 以及传入的 RateLimitedReader 使用 WriterContext 的 ctx
+func TestWriterRateLimit(t *testing.T) {
+	// 突发容量 1KB, 速率 4KB/s, 写出 4KB 至少需要约 0.75s
+	data := testBody(4096)
+	c, conn := newTestContext()
+	start := time.Now()
+	err := WriterWithOptions(&closeRecorder{Reader: bytes.NewReader(data)}, c, Options{Size: 1024, RateLimit: 4096, RateBurst: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("4KB at 4KB/s with 1KB burst took %v, want >= 500ms", elapsed)
+	}
+	if _, body, _ := readResponse(t, c, conn); !bytes.Equal(body, data) {
+		t.Fatalf("body mismatch (got %d bytes)", len(body))
+	}
+
+	// RateLimitedReader 原本的 ctx 永不取消, 替换为 WriterContext 的 ctx 后取消可以打断限速等待
+	c, _ = newTestContext()
+	rlr := limitreader.NewRateLimitedReader(bytes.NewReader(testBody(1024)), 1, 1, context.Background())
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := WriterContext(ctx, rlr, c, Options{RateLimit: rate.Inf}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancel took %v", elapsed)
+	}
+}
+
+// TestWriterProgress 测试 Progress 在每次刷新后以累计字节数调用, 返回前 (包括出错时) 再调用一次
+func TestWriterProgress(t *testing.T) {
+	var got []int64
+	progress := func(written int64) { got = append(got, written) }
+
+	c, _ := newTestContext()
+	src := &closeRecorder{Reader: bytes.NewReader(testBody(5000))}
+	if err := WriterWithOptions(src, c, Options{Size: 1024, FlushEveryBytes: 2048, Progress: progress}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{2048, 4096, 5000}; !slices.Equal(got, want) {
+		t.Errorf("progress = %v, want %v", got, want)
+	}
+
+	got = nil
+	errRead := errors.New("upstream reset")
+	c, _ = newTestContext()
+	src = &closeRecorder{Reader: io.MultiReader(bytes.NewReader(testBody(1024)), iotest.ErrReader(errRead))}
+	if err := WriterWithOptions(src, c, Options{Size: 1024, Progress: progress}); !errors.Is(err, errRead) {
+		t.Fatalf("got %v, want %v", err, errRead)
+	}
+	if want := []int64{1024, 1024}; !slices.Equal(got, want) {
+		t.Errorf("progress on error = %v, want %v", got, want)
+	}
+}
+
+// TestWriterStats 测试 WriterStats 返回写入的字节数与耗时, 出错时返回出错前已写入的字节数
+func TestWriterStats(t *testing.T) {
+	c, conn := newTestContext()
+	data := testBody(3000)
+	written, elapsed, err := WriterStats(context.Background(), &closeRecorder{Reader: bytes.NewReader(data)}, c, Options{Size: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != int64(len(data)) {
+		t.Errorf("written = %d, want %d", written, len(data))
+	}
+	if elapsed <= 0 {
+		t.Errorf("elapsed = %v, want > 0", elapsed)
+	}
+	if _, body, _ := readResponse(t, c, conn); !bytes.Equal(body, data) {
+		t.Fatalf("body mismatch (got %d bytes)", len(body))
+	}
+
+	errRead := errors.New("upstream reset")
+	c, _ = newTestContext()
+	src := &closeRecorder{Reader: io.MultiReader(bytes.NewReader(testBody(2048)), iotest.ErrReader(errRead))}
+	written, _, err = WriterStats(context.Background(), src, c, Options{Size: 1024})
+	if !errors.Is(err, errRead) {
+		t.Fatalf("got %v, want %v", err, errRead)
+	}
+	if written != 2048 {
+		t.Errorf("written on error = %d, want 2048", written)
+	}
+}
+
+// TestWriterSmallBody 测试已知长度的小响应体以普通响应写出, 以及实际长度超过阈值时退回 chunked
+func TestWriterSmallBody(t *testing.T) {
+	data := testBody(100)
+	accept := ut.Header{Key: "Accept-Encoding", Value: "gzip"}
+
+	// 长度来自 Len() 或 ContentLength, 小响应体不压缩
+	for _, tc := range []struct {
+		name string
+		src  io.ReadCloser
+		opts Options
+	}{
+		{"Len", &lenReadCloser{bytes.NewReader(data)}, Options{SmallBodyThreshold: 1024, Compress: true}},
+		{"ContentLength", &closeRecorder{Reader: bytes.NewReader(data)}, Options{SmallBodyThreshold: 1024, ContentLength: 100, Compress: true}},
+		{"at threshold", &lenReadCloser{bytes.NewReader(data)}, Options{SmallBodyThreshold: 100}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := newTestContext(accept)
+			written, _, err := WriterStats(context.Background(), tc.src, c, tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if written != int64(len(data)) {
+				t.Errorf("written = %d, want %d", written, len(data))
+			}
+			if c.Response.GetHijackWriter() != nil {
+				t.Fatal("small body was written with a chunked writer")
+			}
+			if got := c.Response.Body(); !bytes.Equal(got, data) {
+				t.Fatalf("body mismatch (got %d bytes)", len(got))
+			}
+			if ce := c.Response.Header.Get("Content-Encoding"); ce != "" {
+				t.Errorf("Content-Encoding = %q, want none", ce)
+			}
+		})
+	}
+
+	// 长度未知或超过阈值时使用 chunked; 声明的长度与实际不符时, 已读取的部分作为前缀继续以 chunked 写出
+	for _, tc := range []struct {
+		name string
+		src  io.ReadCloser
+		opts Options
+	}{
+		{"unknown length", io.NopCloser(bytes.NewReader(data)), Options{SmallBodyThreshold: 1024}},
+		{"over threshold", &lenReadCloser{bytes.NewReader(data)}, Options{SmallBodyThreshold: 99}},
+		{"short ContentLength", &lenReadCloser{bytes.NewReader(data)}, Options{SmallBodyThreshold: 50, ContentLength: 10}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, conn := newTestContext()
+			if err := WriterWithOptions(tc.src, c, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			resp, body, _ := readResponse(t, c, conn)
+			if len(resp.TransferEncoding) != 1 || resp.TransferEncoding[0] != "chunked" {
+				t.Errorf("Transfer-Encoding = %v, want chunked", resp.TransferEncoding)
+			}
+			if !bytes.Equal(body, data) {
+				t.Fatalf("body mismatch (got %d bytes)", len(body))
+			}
+		})
+	}
+}
+
+// lenReadCloser 是实现了 Len() int 的 io.ReadCloser
+type lenReadCloser struct {
+	*bytes.Reader
+}
+
+func (*lenReadCloser) Close() error { return nil }
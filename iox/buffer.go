@@ -0,0 +1,31 @@
+package iox
+
+import (
+	"io"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// GetBuffer 从池中获取一个空的 ByteBuffer, 用于拼装较小的响应等场景.
+// 使用完毕后应调用 PutBuffer 归还, 归还后不能再使用该缓冲区及其 B 切片.
+func GetBuffer() *bytebufferpool.ByteBuffer {
+	return bytebufferpool.Get()
+}
+
+// PutBuffer 将通过 GetBuffer 获取的 ByteBuffer 归还到池中.
+func PutBuffer(bb *bytebufferpool.ByteBuffer) {
+	bytebufferpool.Put(bb)
+}
+
+// WriteString 将字符串 s 写入 w.
+// 如果 w 实现了 io.StringWriter, 直接调用 WriteString; 否则将 s 拷贝到池化缓冲区后写入,
+// 避免 []byte(s) 转换带来的分配, 且不使用 unsafe.
+func WriteString(w io.Writer, s string) (n int, err error) {
+	if sw, ok := w.(io.StringWriter); ok {
+		return sw.WriteString(s)
+	}
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+	bb.B = append(bb.B[:0], s...)
+	return w.Write(bb.B)
+}
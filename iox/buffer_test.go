@@ -0,0 +1,36 @@
+package iox
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// TestGetPutBuffer 测试从池中获取的缓冲区为空且可以正常写入和归还.
+func TestGetPutBuffer(t *testing.T) {
+	bb := GetBuffer()
+	if bb.Len() != 0 {
+		t.Fatalf("Expected an empty buffer, got %d bytes", bb.Len())
+	}
+	bb.WriteString(testSource)
+	if bb.String() != testSource {
+		t.Errorf("Buffer content mismatch. Got %q, want %q", bb.String(), testSource)
+	}
+	PutBuffer(bb)
+}
+
+// TestWriteString 测试 WriteString 对实现和未实现 io.StringWriter 的目标都能正确写入.
+func TestWriteString(t *testing.T) {
+	dst := new(bytes.Buffer)
+	// bytes.Buffer 实现了 io.StringWriter, 包装后只剩 io.Writer.
+	for _, w := range []io.Writer{dst, struct{ io.Writer }{dst}} {
+		dst.Reset()
+		n, err := WriteString(w, testSource)
+		if err != nil {
+			t.Fatalf("WriteString failed: %v", err)
+		}
+		if n != len(testSource) || dst.String() != testSource {
+			t.Errorf("WriteString wrote %d bytes %q, want %q", n, dst.String(), testSource)
+		}
+	}
+}
@@ -2,4 +2,7 @@ module github.com/WJQSERVER-STUDIO/go-utils/iox
 
 go 1.26
 
-require github.com/valyala/bytebufferpool v1.0.0
+require (
+	github.com/WJQSERVER-STUDIO/go-utils/copyb v0.1.0
+	github.com/valyala/bytebufferpool v1.0.0
+)
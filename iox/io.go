@@ -1,128 +1,67 @@
 package iox
 
 import (
-	"errors"
+	"context"
+	"hash"
 	"io"
 
-	"github.com/valyala/bytebufferpool"
+	"github.com/WJQSERVER-STUDIO/go-utils/copyb"
 )
 
-// errInvalidWrite 表示一次写入返回了一个不可能的计数值.
-// 这是对 io 包中未导出的同名错误的本地实现, 以保持兼容性.
-var errInvalidWrite = errors.New("invalid write result")
+// iox 的池化拷贝 API 直接转发到 copyb, 两者共用同一份实现, 行为的修改只需要在 copyb 中进行.
 
-// copyBuffer 是 Copy 和 CopyBuffer 的核心实现.
-// 如果 buf 为 nil, 则从 bytebufferpool 中获取一个进行池化操作.
-func copyBuffer(dst io.Writer, src io.Reader, buf []byte) (written int64, err error) {
-	// 快速路径: 如果 src 实现了 io.WriterTo 接口, 直接使用它进行拷贝,
-	// 这样可以避免分配和使用中间缓冲区, 效率最高.
-	if wt, ok := src.(io.WriterTo); ok {
-		return wt.WriteTo(dst)
-	}
-
-	// 快速路径: 同样, 如果 dst 实现了 io.ReaderFrom 接口, 也直接使用它进行拷贝.
-	if rf, ok := dst.(io.ReaderFrom); ok {
-		return rf.ReadFrom(src)
-	}
-
-	// 如果外部没有提供缓冲区, 我们从池中获取一个.
-	if buf == nil {
-		// 定义一个进行高效I/O操作的理想缓冲区大小.
-		const defaultBufSize = 32 * 1024
-
-		// 从池中获取一个 ByteBuffer 对象.
-		bb := bytebufferpool.Get()
-		defer bytebufferpool.Put(bb)
-
-		// 检查池中获取的缓冲区容量.
-		// 如果容量小于我们的理想大小, 则进行一次“投资性”分配,
-		// 将其内部的切片替换为一个更大的切片.
-		// 这可以“升级”池中的小缓冲区, 使未来的复用更高效.
-		if cap(bb.B) < defaultBufSize {
-			bb.B = make([]byte, defaultBufSize)
-		}
-
-		buf = bb.B[:defaultBufSize]
-	}
-
-	// 核心拷贝循环, 逻辑与标准库 io.Copy 保持一致, 保证健壮性.
-	for {
-		// 从源读取数据到缓冲区.
-		nr, er := src.Read(buf)
-		if nr > 0 {
-			// 将读取到的数据写入目标.
-			nw, ew := dst.Write(buf[0:nr])
-			// 检查写入的字节数是否合理.
-			if nw < 0 || nr < nw {
-				nw = 0
-				if ew == nil {
-					ew = errInvalidWrite
-				}
-			}
-			written += int64(nw)
-			// 如果写入时发生错误, 立即返回该错误.
-			if ew != nil {
-				err = ew
-				break
-			}
-			// 如果写入的字节数少于读取的字节数, 说明发生了短写入, 返回错误.
-			if nr != nw {
-				err = io.ErrShortWrite
-				break
-			}
-		}
-		// 如果读取时发生错误.
-		if er != nil {
-			// 如果错误是 EOF, 这是正常的流结束信号, 不应作为错误返回.
-			if er != io.EOF {
-				err = er
-			}
-			break
-		}
-	}
-	return written, err
-}
+// ErrLimitExceeded 表示 ReadAllLimit 的数据源超出了允许读取的最大字节数.
+// 它与 copyb.ErrLimitExceeded 是同一个值.
+var ErrLimitExceeded = copyb.ErrLimitExceeded
 
 // CopyBuffer 类似于 io.CopyBuffer, 但在需要时会使用 bytebufferpool 来分配缓冲区.
 // 如果 buf 为 nil, 将从池中获取一个; 如果 buf 长度为0, 会导致 panic.
 // 如果 src 实现了 io.WriterTo 或 dst 实现了 io.ReaderFrom, 将不会使用 buf.
 func CopyBuffer(dst io.Writer, src io.Reader, buf []byte) (written int64, err error) {
-	if buf != nil && len(buf) == 0 {
-		panic("empty buffer in CopyBuffer")
-	}
-	return copyBuffer(dst, src, buf)
+	return copyb.CopyBuffer(dst, src, buf)
 }
 
 // Copy 类似于 io.Copy, 但内部使用 bytebufferpool 来获取临时缓冲区, 以减少内存分配.
 // 这在需要高性能、高并发拷贝大量数据的场景下非常有用.
 func Copy(dst io.Writer, src io.Reader) (written int64, err error) {
-	return copyBuffer(dst, src, nil)
+	return copyb.Copy(dst, src)
+}
+
+// CopyBufferSize 类似于 Copy, 但从池中获取一个 size 字节的缓冲区, 详见 copyb.CopyBufferSize.
+func CopyBufferSize(dst io.Writer, src io.Reader, size int) (written int64, err error) {
+	return copyb.CopyBufferSize(dst, src, size)
+}
+
+// CopyChecksum 类似于 Copy, 但在拷贝的同时将写入 dst 的数据交给 h, 详见 copyb.CopyChecksum.
+func CopyChecksum(dst io.Writer, src io.Reader, h hash.Hash) (written int64, err error) {
+	return copyb.CopyChecksum(dst, src, h)
+}
+
+// NewTeeReader 类似于 io.TeeReader, 返回一个在读取 r 的同时将读到的数据写入 w 的 Reader, 详见 copyb.NewTeeReader.
+func NewTeeReader(r io.Reader, w io.Writer) io.Reader {
+	return copyb.NewTeeReader(r, w)
+}
+
+// CopyContext 类似于 Copy, 但可以通过 ctx 中止拷贝, 详见 copyb.CopyContext.
+func CopyContext(ctx context.Context, dst io.Writer, src io.Reader) (written int64, err error) {
+	return copyb.CopyContext(ctx, dst, src)
+}
+
+// CopyWithProgress 类似于 Copy, 但每次成功写入一个缓冲区后都会以累计写入的字节数调用 fn, 详见 copyb.CopyWithProgress.
+func CopyWithProgress(dst io.Writer, src io.Reader, fn func(written int64)) (written int64, err error) {
+	return copyb.CopyWithProgress(dst, src, fn)
+}
+
+// CopyAdaptive 类似于 Copy, 但会根据实际读取情况自动调整池化缓冲区的大小, 详见 copyb.CopyAdaptive.
+func CopyAdaptive(dst io.Writer, src io.Reader) (written int64, err error) {
+	return copyb.CopyAdaptive(dst, src)
 }
 
 // CopyN 从 src 拷贝 n 字节数据到 dst (或在遇到错误时提前停止).
 // 它返回拷贝的字节数和拷贝时遇到的第一个错误.
 // 仅当 err == nil 时, written == n 才会成立.
-//
-// 此实现利用了池化的 copyb.Copy 和标准的 io.LimitReader, 以实现高性能.
 func CopyN(dst io.Writer, src io.Reader, n int64) (written int64, err error) {
-	// 使用我们池化的 Copy 函数和标准的 LimitReader 来执行拷贝.
-	// LimitReader 会确保我们从 src 中最多只读取 n 个字节.
-	written, err = Copy(dst, io.LimitReader(src, n))
-
-	// 根据 io.CopyN 的约定处理返回值.
-	if written == n {
-		// 如果我们成功拷贝了 n 个字节, LimitReader 会遇到 EOF,
-		// 但我们的 Copy 函数会将其视作正常结束, 返回 err = nil.
-		// 这是成功的标志, 所以我们确保返回 nil 错误.
-		return n, nil
-	}
-	if written < n && err == nil {
-		// 如果拷贝的字节数少于 n, 并且没有发生其他错误,
-		// 这意味着源 (src) 的数据提前结束了.
-		// 在这种情况下, 必须返回 io.EOF 错误.
-		err = io.EOF
-	}
-	return
+	return copyb.CopyN(dst, src, n)
 }
 
 // ReadAll reads from r until EOF.
@@ -133,3 +72,9 @@ func CopyN(dst io.Writer, src io.Reader, n int64) (written int64, err error) {
 func ReadAll(r io.Reader) ([]byte, error) {
 	return io.ReadAll(r)
 }
+
+// ReadAllLimit 类似于 ReadAll, 但最多只读取 max 字节.
+// 如果 r 产生的数据超过 max 字节, 返回 ErrLimitExceeded, 适合读取不可信的请求体等场景.
+func ReadAllLimit(r io.Reader, max int64) ([]byte, error) {
+	return copyb.ReadAllLimit(r, max)
+}
@@ -2,10 +2,17 @@ package iox
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"testing"
+	"testing/iotest"
+
+	"github.com/WJQSERVER-STUDIO/go-utils/copyb"
 )
 
 // testSource 是一个用于测试的字符串, 包含了各种字符.
@@ -166,6 +173,107 @@ func TestCopyN(t *testing.T) {
 	})
 }
 
+// TestCopybParity 测试 iox 的拷贝 API 与 copyb 对相同输入产生相同的结果.
+// iox 直接转发到 copyb, 完整的行为测试位于 copyb 中, 这里只保证每个函数都转发到了对应的实现.
+func TestCopybParity(t *testing.T) {
+	type result struct {
+		written int64
+		out     string
+		err     error
+	}
+	type copyFunc func(dst io.Writer, src io.Reader) (int64, error)
+
+	// 每个源都会重新创建, 覆盖 io.WriterTo 快速路径、普通拷贝循环以及读取出错的情况
+	sources := map[string]func() io.Reader{
+		"WriterTo":  func() io.Reader { return strings.NewReader(testSource) },
+		"OneByte":   func() io.Reader { return iotest.OneByteReader(strings.NewReader(testSource)) },
+		"ReadError": func() io.Reader { return &faultyReader{readsBeforeFailure: 3} },
+	}
+	progress := func(calls *[]int64) func(int64) {
+		return func(n int64) { *calls = append(*calls, n) }
+	}
+	var ioxCalls, copybCalls []int64
+	funcs := map[string][2]copyFunc{
+		"Copy":         {Copy, copyb.Copy},
+		"CopyAdaptive": {CopyAdaptive, copyb.CopyAdaptive},
+		"CopyBuffer": {
+			func(d io.Writer, s io.Reader) (int64, error) { return CopyBuffer(d, s, make([]byte, 16)) },
+			func(d io.Writer, s io.Reader) (int64, error) { return copyb.CopyBuffer(d, s, make([]byte, 16)) },
+		},
+		"CopyBufferSize": {
+			func(d io.Writer, s io.Reader) (int64, error) { return CopyBufferSize(d, s, 16) },
+			func(d io.Writer, s io.Reader) (int64, error) { return copyb.CopyBufferSize(d, s, 16) },
+		},
+		"CopyN": {
+			func(d io.Writer, s io.Reader) (int64, error) { return CopyN(d, s, 10) },
+			func(d io.Writer, s io.Reader) (int64, error) { return copyb.CopyN(d, s, 10) },
+		},
+		"CopyContext": {
+			func(d io.Writer, s io.Reader) (int64, error) { return CopyContext(context.Background(), d, s) },
+			func(d io.Writer, s io.Reader) (int64, error) { return copyb.CopyContext(context.Background(), d, s) },
+		},
+		"CopyWithProgress": {
+			func(d io.Writer, s io.Reader) (int64, error) { return CopyWithProgress(d, s, progress(&ioxCalls)) },
+			func(d io.Writer, s io.Reader) (int64, error) {
+				return copyb.CopyWithProgress(d, s, progress(&copybCalls))
+			},
+		},
+		"CopyChecksum": {
+			func(d io.Writer, s io.Reader) (int64, error) {
+				h := sha256.New()
+				n, err := CopyChecksum(d, s, h)
+				d.Write(h.Sum(nil))
+				return n, err
+			},
+			func(d io.Writer, s io.Reader) (int64, error) {
+				h := sha256.New()
+				n, err := copyb.CopyChecksum(d, s, h)
+				d.Write(h.Sum(nil))
+				return n, err
+			},
+		},
+		"NewTeeReader": {
+			func(d io.Writer, s io.Reader) (int64, error) { return io.Copy(io.Discard, NewTeeReader(s, d)) },
+			func(d io.Writer, s io.Reader) (int64, error) { return io.Copy(io.Discard, copyb.NewTeeReader(s, d)) },
+		},
+		"ReadAllLimit": {
+			func(d io.Writer, s io.Reader) (int64, error) {
+				b, err := ReadAllLimit(s, 10)
+				d.Write(b)
+				return 0, err
+			},
+			func(d io.Writer, s io.Reader) (int64, error) {
+				b, err := copyb.ReadAllLimit(s, 10)
+				d.Write(b)
+				return 0, err
+			},
+		},
+	}
+
+	run := func(f copyFunc, src io.Reader) result {
+		// 使用不实现 io.ReaderFrom 的写入目标, 避免绕过池化缓冲区
+		var out strings.Builder
+		n, err := f(struct{ io.Writer }{&out}, src)
+		return result{n, out.String(), err}
+	}
+	for fname, f := range funcs {
+		for sname, src := range sources {
+			ioxCalls, copybCalls = nil, nil
+			got, want := run(f[0], src()), run(f[1], src())
+			if got.written != want.written || got.out != want.out || fmt.Sprint(got.err) != fmt.Sprint(want.err) {
+				t.Errorf("%s/%s: iox = %+v, copyb = %+v", fname, sname, got, want)
+			}
+			if !slices.Equal(ioxCalls, copybCalls) {
+				t.Errorf("%s/%s: progress iox = %v, copyb = %v", fname, sname, ioxCalls, copybCalls)
+			}
+		}
+	}
+
+	if _, err := ReadAllLimit(strings.NewReader(testSource), 1); !errors.Is(err, copyb.ErrLimitExceeded) {
+		t.Errorf("ReadAllLimit: got %v, want copyb.ErrLimitExceeded", err)
+	}
+}
+
 // --- 基准测试 (Benchmarks) ---
 
 // a large buffer for benchmarking
@@ -0,0 +1,84 @@
+package iox
+
+import (
+	"errors"
+	"io"
+
+	"github.com/valyala/bytebufferpool"
+)
+
+// ErrPartTooLarge 表示单个分段的数据超出了 PartReader 的字节上限.
+var ErrPartTooLarge = errors.New("iox: part too large")
+
+// PartReader 为单个分段 (例如 mime/multipart 的一个 Part) 提供有上限的读取.
+// 它不解析 multipart, 只负责按分段限制读取的字节数, 并通过 ReadAll 使用池化缓冲区读取整个分段.
+// 处理多个分段时可以用 Reset 复用同一个 PartReader.
+type PartReader struct {
+	r   io.Reader
+	max int64 // 单个分段允许的最大字节数
+	n   int64 // 当前分段已读取的字节数
+}
+
+// NewPartReader 创建一个从 r 读取、最多允许 max 字节的 PartReader.
+func NewPartReader(r io.Reader, max int64) *PartReader {
+	return &PartReader{r: r, max: max}
+}
+
+// Reset 将 PartReader 切换到新的分段 r, 并清零已读计数, 字节上限保持不变.
+func (p *PartReader) Reset(r io.Reader) {
+	p.r = r
+	p.n = 0
+}
+
+// Read 实现 io.Reader 接口.
+// 当分段数据超过上限时, 返回上限以内的数据以及 ErrPartTooLarge, 之后的读取均返回 ErrPartTooLarge.
+func (p *PartReader) Read(b []byte) (n int, err error) {
+	if p.n > p.max {
+		return 0, ErrPartTooLarge
+	}
+	// 多读一个字节用于判断是否超出上限
+	if remaining := p.max - p.n + 1; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+	n, err = p.r.Read(b)
+	p.n += int64(n)
+	if p.n > p.max {
+		n -= int(p.n - p.max)
+		return n, ErrPartTooLarge
+	}
+	return n, err
+}
+
+// ReadAll 使用池化的缓冲区读取整个分段并返回数据副本.
+// 分段超过上限时返回 ErrPartTooLarge.
+func (p *PartReader) ReadAll() ([]byte, error) {
+	bb := bytebufferpool.Get()
+	defer bytebufferpool.Put(bb)
+
+	// 直接在池化缓冲区上循环读取. 缓冲区写满时先读入一小块临时缓冲区再追加,
+	// 避免仅为探测 EOF 而扩容, 使池中缓冲区的容量保持与分段大小相当.
+	var probe [512]byte
+	buf := bb.B[:0]
+	for {
+		var n int
+		var err error
+		if len(buf) < cap(buf) {
+			n, err = p.Read(buf[len(buf):cap(buf)])
+			buf = buf[:len(buf)+n]
+		} else {
+			n, err = p.Read(probe[:])
+			buf = append(buf, probe[:n]...)
+		}
+		if err != nil {
+			bb.B = buf
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+	}
+
+	b := make([]byte, len(bb.B))
+	copy(b, bb.B)
+	return b, nil
+}
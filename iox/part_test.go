@@ -0,0 +1,100 @@
+package iox
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// TestPartReaderWithinLimit 测试分段大小不超过上限时能完整读取.
+func TestPartReaderWithinLimit(t *testing.T) {
+	for _, max := range []int64{int64(len(testSource)), int64(len(testSource)) + 10} {
+		pr := NewPartReader(strings.NewReader(testSource), max)
+		data, err := pr.ReadAll()
+		if err != nil {
+			t.Fatalf("max=%d: ReadAll failed: %v", max, err)
+		}
+		if string(data) != testSource {
+			t.Errorf("max=%d: got %q, want %q", max, data, testSource)
+		}
+	}
+}
+
+// TestPartReaderTooLarge 测试分段超过上限时返回 ErrPartTooLarge, 且不会多读出上限以外的数据.
+func TestPartReaderTooLarge(t *testing.T) {
+	const max = 10
+	pr := NewPartReader(strings.NewReader(testSource), max)
+
+	dst := new(bytes.Buffer)
+	_, err := io.Copy(dst, struct{ io.Reader }{pr})
+	if !errors.Is(err, ErrPartTooLarge) {
+		t.Fatalf("expected ErrPartTooLarge, got %v", err)
+	}
+	if dst.String() != testSource[:max] {
+		t.Errorf("expected %q before the limit, got %q", testSource[:max], dst.String())
+	}
+
+	if _, err := pr.Read(make([]byte, 1)); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("expected ErrPartTooLarge on subsequent Read, got %v", err)
+	}
+
+	if _, err := NewPartReader(strings.NewReader(testSource), max).ReadAll(); !errors.Is(err, ErrPartTooLarge) {
+		t.Errorf("expected ErrPartTooLarge from ReadAll, got %v", err)
+	}
+}
+
+// TestPartReaderReset 测试 Reset 后计数清零, 可用于读取下一个分段.
+func TestPartReaderReset(t *testing.T) {
+	pr := NewPartReader(strings.NewReader("0123456789"), 5)
+	if _, err := pr.ReadAll(); !errors.Is(err, ErrPartTooLarge) {
+		t.Fatalf("expected ErrPartTooLarge, got %v", err)
+	}
+
+	pr.Reset(strings.NewReader("abc"))
+	data, err := pr.ReadAll()
+	if err != nil {
+		t.Fatalf("ReadAll after Reset failed: %v", err)
+	}
+	if string(data) != "abc" {
+		t.Errorf("got %q, want %q", data, "abc")
+	}
+}
+
+// --- 基准测试 (Benchmarks) ---
+
+// smallPart 模拟一个较小的上传分段.
+var smallPart = strings.Repeat("x", 2048)
+
+const partLimit = 4096
+
+// BenchmarkPartLimitReaderReadAll 是 io.LimitReader + io.ReadAll 的朴素实现基准.
+func BenchmarkPartLimitReaderReadAll(b *testing.B) {
+	src := strings.NewReader(smallPart)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src.Seek(0, io.SeekStart)
+		data, err := io.ReadAll(io.LimitReader(src, partLimit+1))
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(data) > partLimit {
+			b.Fatal(ErrPartTooLarge)
+		}
+	}
+}
+
+// BenchmarkPartReaderReadAll 是复用 PartReader 读取分段的基准.
+func BenchmarkPartReaderReadAll(b *testing.B) {
+	src := strings.NewReader(smallPart)
+	pr := NewPartReader(src, partLimit)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		src.Seek(0, io.SeekStart)
+		pr.Reset(src)
+		if _, err := pr.ReadAll(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
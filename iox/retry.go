@@ -0,0 +1,81 @@
+package iox
+
+import (
+	"io"
+	"time"
+)
+
+// maxRetryBackoff 是指数退避的等待上限, 避免 backoff << attempt 溢出或等待过久.
+const maxRetryBackoff = time.Minute
+
+// retryReader 是 RetryReader 返回的 io.Reader.
+type retryReader struct {
+	r           io.Reader
+	seeker      io.Seeker // r 可 Seek 时非 nil
+	shouldRetry func(error) bool
+	maxRetries  int
+	backoff     time.Duration
+}
+
+// RetryReader 返回一个在底层读取遇到临时错误时自动重试的 io.Reader.
+// shouldRetry 判断错误是否可重试 (io.EOF 永远不会重试), 为 nil 时不重试; 每次 Read 最多重试 maxRetries 次,
+// 第 i 次重试前等待 backoff << i, 即指数退避, 单次等待不超过 1 分钟 (backoff 本身更大时以 backoff 为准).
+//
+// 限制:
+//   - 只有在本次 Read 尚未读到任何数据时才会重试. 如果底层 Read 在返回错误的同时返回了数据,
+//     这些数据会先交给调用方 (此次 Read 返回 nil 错误), 错误留待下一次 Read 时重新触发并重试.
+//   - 如果 r 实现了 io.Seeker, 每次重试前会把读取位置恢复到本次 Read 开始前,
+//     以防出错的读取已经推进了位置; 否则只能假设失败的读取没有消费任何数据.
+//   - 重试只针对同一个流, 无法恢复已经断开且不可重新读取的连接.
+func RetryReader(r io.Reader, shouldRetry func(error) bool, maxRetries int, backoff time.Duration) io.Reader {
+	rr := &retryReader{
+		r:           r,
+		shouldRetry: shouldRetry,
+		maxRetries:  maxRetries,
+		backoff:     backoff,
+	}
+	if s, ok := r.(io.Seeker); ok {
+		rr.seeker = s
+	}
+	return rr
+}
+
+// Read 实现 io.Reader 接口.
+func (rr *retryReader) Read(p []byte) (int, error) {
+	// 记录本次读取开始前的位置, 用于重试前恢复. 获取失败时视为不可 Seek.
+	pos := int64(-1)
+	if rr.seeker != nil {
+		if off, err := rr.seeker.Seek(0, io.SeekCurrent); err == nil {
+			pos = off
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		n, err := rr.r.Read(p)
+		if err == nil || err == io.EOF || rr.shouldRetry == nil || !rr.shouldRetry(err) {
+			return n, err
+		}
+		if n > 0 {
+			// 已读到的数据是有效的, 先交给调用方, 错误在下一次 Read 时重新触发.
+			return n, nil
+		}
+		if attempt >= rr.maxRetries {
+			return 0, err
+		}
+		if pos >= 0 {
+			if _, serr := rr.seeker.Seek(pos, io.SeekStart); serr != nil {
+				return 0, err
+			}
+		}
+		time.Sleep(rr.delay(attempt))
+	}
+}
+
+// delay 返回第 attempt 次重试前的等待时间, 翻倍到 maxRetryBackoff 后不再增长.
+func (rr *retryReader) delay(attempt int) time.Duration {
+	d := rr.backoff
+	for i := 0; i < attempt && d > 0 && d < maxRetryBackoff; i++ {
+		d <<= 1
+	}
+	return min(d, max(rr.backoff, maxRetryBackoff))
+}
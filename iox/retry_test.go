@@ -0,0 +1,117 @@
+package iox
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// errTransient 是测试中使用的可重试错误.
+var errTransient = errors.New("transient error")
+
+func isTransient(err error) bool { return errors.Is(err, errTransient) }
+
+// flakyReader 每次读取成功前先失败 failures 次.
+type flakyReader struct {
+	r        io.Reader
+	failures int
+	failed   int
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	if f.failed < f.failures {
+		f.failed++
+		return 0, errTransient
+	}
+	f.failed = 0
+	return f.r.Read(p)
+}
+
+// TestRetryReaderRecovers 测试读取在失败后能通过重试恢复, 并读到完整数据.
+func TestRetryReaderRecovers(t *testing.T) {
+	src := &flakyReader{r: strings.NewReader(testSource), failures: 2}
+	data, err := io.ReadAll(RetryReader(src, isTransient, 3, 0))
+	if err != nil {
+		t.Fatalf("expected retries to recover, got %v", err)
+	}
+	if string(data) != testSource {
+		t.Errorf("got %q, want %q", data, testSource)
+	}
+}
+
+// TestRetryReaderGivesUp 测试超过重试次数或遇到不可重试的错误时返回错误.
+func TestRetryReaderGivesUp(t *testing.T) {
+	src := &flakyReader{r: strings.NewReader(testSource), failures: 3}
+	if _, err := io.ReadAll(RetryReader(src, isTransient, 2, 0)); !errors.Is(err, errTransient) {
+		t.Errorf("expected errTransient after exhausting retries, got %v", err)
+	}
+
+	src = &flakyReader{r: strings.NewReader(testSource), failures: 1}
+	never := func(error) bool { return false }
+	if _, err := io.ReadAll(RetryReader(src, never, 5, 0)); !errors.Is(err, errTransient) {
+		t.Errorf("expected non-retryable error to be returned, got %v", err)
+	}
+}
+
+// advancingReader 在失败的读取中仍然推进了读取位置, 模拟状态不确定的数据源.
+type advancingReader struct {
+	*strings.Reader
+	failed bool
+}
+
+func (a *advancingReader) Read(p []byte) (int, error) {
+	if !a.failed {
+		a.failed = true
+		a.Reader.Seek(5, io.SeekCurrent)
+		return 0, errTransient
+	}
+	return a.Reader.Read(p)
+}
+
+// TestRetryReaderSeeksBack 测试可 Seek 的数据源在重试前会恢复到读取开始前的位置.
+func TestRetryReaderSeeksBack(t *testing.T) {
+	src := &advancingReader{Reader: strings.NewReader(testSource)}
+	data, err := io.ReadAll(RetryReader(src, isTransient, 1, 0))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if string(data) != testSource {
+		t.Errorf("got %q, want %q", data, testSource)
+	}
+}
+
+// TestRetryReaderNilShouldRetry 测试 shouldRetry 为 nil 时不重试, 直接返回错误.
+func TestRetryReaderNilShouldRetry(t *testing.T) {
+	src := &flakyReader{r: strings.NewReader(testSource), failures: 1}
+	if _, err := io.ReadAll(RetryReader(src, nil, 5, 0)); !errors.Is(err, errTransient) {
+		t.Fatalf("got %v, want %v", err, errTransient)
+	}
+}
+
+// TestRetryReaderBackoffCapped 测试退避时间翻倍到上限后不再增长, 重试次数很大时也不会溢出.
+func TestRetryReaderBackoffCapped(t *testing.T) {
+	rr := RetryReader(nil, nil, 0, time.Second).(*retryReader)
+	for attempt, want := range map[int]time.Duration{
+		0:       time.Second,
+		1:       2 * time.Second,
+		5:       32 * time.Second,
+		6:       maxRetryBackoff,
+		40:      maxRetryBackoff,
+		1 << 20: maxRetryBackoff,
+	} {
+		if got := rr.delay(attempt); got != want {
+			t.Errorf("delay(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+
+	rr.backoff = 2 * time.Hour // 超过上限的 backoff 原样使用
+	if got := rr.delay(3); got != 2*time.Hour {
+		t.Errorf("delay with large backoff = %v, want 2h", got)
+	}
+	rr.backoff = 0
+	if got := rr.delay(100); got != 0 {
+		t.Errorf("delay with zero backoff = %v, want 0", got)
+	}
+}
@@ -0,0 +1,16 @@
+package limitreader
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// CopyRateLimited 将 src 包装为 RateLimitedReader 后复制到 dst，返回复制的字节数。
+// limit、burst 与 ctx 的含义同 NewRateLimitedReader。
+// 复制直接使用 RateLimitedReader.WriteTo，每次读写不超过一个突发容量，不经过 dst 的 ReaderFrom 等快速路径。
+// ctx 被取消时复制立即停止，并返回 ctx.Err()。
+func CopyRateLimited(dst io.Writer, src io.Reader, limit rate.Limit, burst int, ctx context.Context) (int64, error) {
+	return NewRateLimitedReader(src, limit, burst, ctx).WriteTo(dst)
+}
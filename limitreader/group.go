@@ -0,0 +1,47 @@
+package limitreader
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// --- 分组限速器 ---
+
+var (
+	// groupLimiters 保存按名称注册的分组限速器。
+	// 分组限速器创建后不会被替换，修改速率时原地更新，因此已创建的读取器会立即感知变化。
+	groupLimitersMu sync.Mutex
+	groupLimiters   = make(map[string]*rate.Limiter)
+)
+
+// GetGroupLimiter 返回名为 name 的分组限速器，不存在时创建一个无限速的分组。
+// 返回的限速器被该分组的所有读取器共享。
+func GetGroupLimiter(name string) *rate.Limiter {
+	groupLimitersMu.Lock()
+	defer groupLimitersMu.Unlock()
+	lim, ok := groupLimiters[name]
+	if !ok {
+		lim = rate.NewLimiter(rate.Inf, 0)
+		groupLimiters[name] = lim
+	}
+	return lim
+}
+
+// SetGroupRateLimit 设置名为 name 的分组的速率限制，分组不存在时创建。
+// limit: 分组速率限制，单位是 Bytes/s (rate.Limit)。
+// burst: 分组令牌桶的突发容量，单位是字节，<= 0 时默认为每秒速率对应的字节数。
+// 将 limit 设置为 <= 0 或 rate.Inf 将禁用该分组的限速。
+// 可以在运行时调用，修改会对该分组已有的读取器生效。
+func SetGroupRateLimit(name string, limit rate.Limit, burst int) {
+	lim := GetGroupLimiter(name)
+	if limit <= 0 || limit == rate.Inf {
+		lim.SetLimit(rate.Inf)
+		return
+	}
+	if burst <= 0 {
+		burst = defaultBurst(limit)
+	}
+	lim.SetBurst(burst)
+	lim.SetLimit(limit)
+}
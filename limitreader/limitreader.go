@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync/atomic" // 引入 atomic 包
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -28,7 +30,7 @@ func init() {
 
 // SetGlobalRateLimit 设置全局读取速率限制。
 // limit: 全局速率限制，单位是 Bytes/s (rate.Limit)。rate.Inf 表示无限制。
-// burst: 全局令牌桶的突发容量，单位是字节。
+// burst: 全局令牌桶的突发容量，单位是字节，<= 0 时默认为每秒速率对应的字节数。
 // 将 limit 设置为 <= 0 或 rate.Inf 将禁用全局限速。
 // !! 重要提示: 此函数应仅在应用程序初始化期间调用。
 // !! 在 RateLimitedReader 实例创建后更改全局限制将不会反映在这些实例的缓存状态中。
@@ -38,66 +40,255 @@ func SetGlobalRateLimit(limit rate.Limit, burst int) {
 	if limit <= 0 || limit == rate.Inf {
 		newLimiter = rate.NewLimiter(rate.Inf, 0)
 	} else {
+		if burst <= 0 {
+			// 突发容量为 0 的限速器永远不会发放令牌
+			burst = defaultBurst(limit)
+		}
 		newLimiter = rate.NewLimiter(limit, burst)
 	}
 	// 原子地存储新的 limiter 指针，替换旧的
 	globalLimiter.Store(newLimiter)
 }
 
-// --- 限速读取器 RateLimitedReader ---
+// --- 限速状态 throttle ---
 
-// RateLimitedReader 包装一个 io.Reader，并应用速率限制。
-// 它同时受自身独立限速器和全局限速器的约束。
-type RateLimitedReader struct {
-	r       io.Reader       // 原始读取器 (如: resp.Body)
+// throttle 保存独立限速器、分组限速器以及创建时缓存的全局限速状态，由 RateLimitedReader 与 RateLimitedWriter 共用。
+type throttle struct {
 	limiter *rate.Limiter   // 独立令牌桶限速器
+	group   *rate.Limiter   // 所属分组的共享限速器，未指定分组时为 nil
 	ctx     context.Context // 用于取消等待的 Context (通常是请求的 Context)
 
 	// 缓存的状态，基于全局限制运行时不变动的假设。
-	// 这些状态在 NewRateLimitedReader 中确定一次。
-	globalLimitActiveAtCreation     bool // 创建时全局限速是否开启
-	individualLimitActiveAtCreation bool // 创建时独立限速是否开启
-	bypassLimiting                  bool // 创建时，如果全局和独立都无限速，则为 true
+	// 全局状态在创建读取器或写入器时确定一次；独立限速状态可以通过 SetLimit 修改。
+	globalLimitActiveAtCreation bool        // 创建时全局限速是否开启
+	individualLimitActive       atomic.Bool // 独立限速是否开启
+	bypassLimiting              atomic.Bool // 如果全局和独立都无限速，则为 true
+}
+
+// setup 根据独立限速参数和当前的全局限速器初始化 throttle。
+// 将 limit 设置为 <= 0 或 rate.Inf 将禁用独立限速。
+func (t *throttle) setup(limit rate.Limit, burst int, ctx context.Context) {
+	t.setContext(ctx)
+	t.limiter = rate.NewLimiter(rate.Inf, 0) // 默认无限速
+
+	// 确定创建实例时全局限速器的激活状态。
+	// 使用 atomic.Load() 安全地获取当前的全局限速器。
+	currentGlobalLimiterAtCreation := globalLimiter.Load()
+	t.globalLimitActiveAtCreation = currentGlobalLimiterAtCreation.Limit() != rate.Inf
+
+	t.setLimit(limit, burst)
+}
+
+// setContext 设置等待时使用的 Context，nil 视为 context.Background()。
+func (t *throttle) setContext(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t.ctx = ctx
+}
+
+// setLimit 更新独立限速器，并重新确定是否可以完全绕过限速。
+// 独立限速器始终是同一个实例，rate.Limiter 的 SetLimit/SetBurst 本身是并发安全的。
+func (t *throttle) setLimit(limit rate.Limit, burst int) {
+	if limit > 0 && limit != rate.Inf {
+		if burst <= 0 {
+			// 突发容量为 0 的限速器永远不会发放令牌
+			burst = defaultBurst(limit)
+		}
+		t.limiter.SetBurst(burst)
+		t.limiter.SetLimit(limit)
+		// 先开启独立限速再取消透穿，避免并发的 Read 在中间状态下跳过限速
+		t.individualLimitActive.Store(true)
+		t.bypassLimiting.Store(false)
+		return
+	}
+	t.limiter.SetLimit(rate.Inf)
+	// 分组限速可以在运行时修改，指定了分组时不能透穿
+	t.bypassLimiting.Store(!t.globalLimitActiveAtCreation && t.group == nil)
+	t.individualLimitActive.Store(false)
+}
+
+// wait 根据缓存的状态向激活的限速器申请 n 个字节的许可。
+func (t *throttle) wait(n int) error {
+	// 如果执行到这里，说明创建时至少有一个限速器是激活的。
+	// 我们根据创建时缓存的状态来决定是否需要等待。
+
+	// 如果创建时全局限速是激活的，则应用全局限速。
+	// 需要获取当前的全局限速器实例来调用 WaitN。
+	// 在运行时不变动的假设下，globalLimiter.Load() 将始终返回同一个 rate.Limiter 实例的指针。
+	if t.globalLimitActiveAtCreation {
+		// 加载当前的全局限速器实例 (使用 atomic.Load)
+		currentGlobalLimiter := globalLimiter.Load()
+		// WaitN 会阻塞直到有令牌或 Context 被取消
+		if err := currentGlobalLimiter.WaitN(t.ctx, n); err != nil {
+			return err
+		}
+	}
+
+	// 如果指定了分组，则应用分组的共享限速。分组限速为 rate.Inf 时 WaitN 立即返回。
+	if t.group != nil {
+		if err := t.group.WaitN(t.ctx, n); err != nil {
+			return err
+		}
+	}
+
+	// 如果独立限速是激活的，则应用独立限速。
+	if t.individualLimitActive.Load() {
+		// t.limiter 是该实例的独立限速器，直接调用 WaitN
+		if err := t.limiter.WaitN(t.ctx, n); err != nil {
+			// WaitN 内部会检查 Context，即使在全局等待时 Context 已取消，这里也会正确处理
+			return err
+		}
+	}
+	return nil
+}
+
+// charge 在读取完成后按实际读取的 n 个字节向激活的限速器计费，并等待到这些令牌可用。
+// 与读取前按 len(p) 申请相比，短读不会多扣令牌，相当于把未使用的令牌退还给两个限速器。
+// n 不能超过限速器的突发容量，调用方需要事先用 maxChunk 截断缓冲区。
+func (t *throttle) charge(n int) error {
+	now := time.Now()
+	var reservations [3]*rate.Reservation
+	var delay time.Duration
+	reserve := func(i int, lim *rate.Limiter) error {
+		r := lim.ReserveN(now, n)
+		if !r.OK() {
+			return fmt.Errorf("rate: Wait(n=%d) exceeds limiter's burst %d", n, lim.Burst())
+		}
+		reservations[i] = r
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+		}
+		return nil
+	}
+	cancel := func() {
+		for _, r := range reservations {
+			if r != nil {
+				r.Cancel() // 尚未到期的预约可以退还令牌
+			}
+		}
+	}
+
+	if t.globalLimitActiveAtCreation {
+		if err := reserve(0, globalLimiter.Load()); err != nil {
+			return err
+		}
+	}
+	if t.group != nil {
+		if err := reserve(1, t.group); err != nil {
+			cancel()
+			return err
+		}
+	}
+	if t.individualLimitActive.Load() {
+		if err := reserve(2, t.limiter); err != nil {
+			cancel()
+			return err
+		}
+	}
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-t.ctx.Done():
+		cancel()
+		return t.ctx.Err()
+	}
+}
+
+// maxChunk 返回单次可申请的最大字节数，即激活的限速器中最小的突发容量；无约束时返回 0。
+func (t *throttle) maxChunk() int {
+	chunk := 0
+	if t.globalLimitActiveAtCreation {
+		chunk = globalLimiter.Load().Burst()
+	}
+	if t.group != nil && t.group.Limit() != rate.Inf {
+		if b := t.group.Burst(); chunk == 0 || b < chunk {
+			chunk = b
+		}
+	}
+	if t.individualLimitActive.Load() {
+		if b := t.limiter.Burst(); chunk == 0 || b < chunk {
+			chunk = b
+		}
+	}
+	return chunk
+}
+
+// --- 限速读取器 RateLimitedReader ---
+
+// RateLimitedReader 包装一个 io.Reader，并应用速率限制。
+// 它同时受自身独立限速器和全局限速器的约束。
+// 原始读取器只保存在未导出字段中，不会嵌入，因此即使原始读取器实现了 io.WriterTo (如 *bytes.Reader)，
+// io.Copy、copyb.Copy 等快速路径也只能使用 RateLimitedReader 自身受限速约束的 WriteTo，
+// 目标实现 io.ReaderFrom 时同样只能通过受限速约束的 Read 读取，不会绕过限速。
+// !! 注意: 不要将原始读取器与 RateLimitedReader 组合成匿名结构体或其他暴露原始读取器方法的包装，否则快速路径会直接读取原始读取器。
+type RateLimitedReader struct {
+	r         io.Reader    // 原始读取器 (如: resp.Body)
+	bytesRead atomic.Int64 // 累计读取的字节数
+	throttle
 }
 
 // NewRateLimitedReader 创建一个新的 RateLimitedReader。
 // r: 底层的读取器 (如: resp.Body)。
 // limit: 独立速率限制，单位是 Bytes/s (rate.Limit)。rate.Inf 表示无限制。
-// burst: 独立令牌桶的突发容量，单位是字节。
-// ctx: 与操作关联的 Context (如: 请求 Context)。
+// burst: 独立令牌桶的突发容量，单位是字节，<= 0 时默认为每秒速率对应的字节数。
+// ctx: 与操作关联的 Context (如: 请求 Context)，nil 视为 context.Background()。
 // 将 limit 设置为 <= 0 或 rate.Inf 将禁用此读取器的独立限速。
 // 全局限制的激活状态是基于调用此函数时全局限制的状态确定的。
 // !! 重要提示: 此 RateLimitedReader 的全局限速行为将固定为创建此实例时的全局状态。
 func NewRateLimitedReader(r io.Reader, limit rate.Limit, burst int, ctx context.Context) *RateLimitedReader {
-	// 确定独立限速器的激活状态
-	individualLimiter := rate.NewLimiter(rate.Inf, 0) // 默认无限速
-	individualLimitActive := false
-	if limit > 0 && limit != rate.Inf {
-		individualLimiter = rate.NewLimiter(limit, burst)
-		individualLimitActive = true
-	}
+	rlr := &RateLimitedReader{r: r}
+	rlr.setup(limit, burst, ctx)
+	return rlr
+}
 
-	// 确定创建 RateLimitedReader 实例时全局限速器的激活状态。
-	// 使用 atomic.Load() 安全地获取当前的全局限速器。
-	currentGlobalLimiterAtCreation := globalLimiter.Load()
-	globalLimitActive := currentGlobalLimiterAtCreation.Limit() != rate.Inf
+// NewRateLimitedReaderSimple 创建一个使用 context.Background() 的 RateLimitedReader，
+// 适用于没有请求 Context 的调用方，之后可以通过 SetContext 关联 Context。其余参数含义同 NewRateLimitedReader。
+func NewRateLimitedReaderSimple(r io.Reader, limit rate.Limit, burst int) *RateLimitedReader {
+	return NewRateLimitedReader(r, limit, burst, context.Background())
+}
 
-	// 确定是否可以完全绕过限速
-	bypass := !globalLimitActive && !individualLimitActive
+// NewRateLimitedReaderWithGroup 创建一个额外受分组共享限速约束的 RateLimitedReader。
+// group: 分组名称，使用同一分组创建的读取器共享同一个令牌桶，分组的速率通过 SetGroupRateLimit 设置。
+// 其余参数含义同 NewRateLimitedReader。与全局限速不同，分组限速在运行时修改后会立即对已创建的读取器生效。
+func NewRateLimitedReaderWithGroup(r io.Reader, group string, limit rate.Limit, burst int, ctx context.Context) *RateLimitedReader {
+	rlr := &RateLimitedReader{r: r}
+	rlr.group = GetGroupLimiter(group)
+	rlr.setup(limit, burst, ctx)
+	return rlr
+}
 
-	return &RateLimitedReader{
-		r:       r,
-		limiter: individualLimiter,
-		ctx:     ctx,
+// NewRateLimitedReaderFromString 解析速率字符串并创建 RateLimitedReader。
+// rateStr: 速率字符串，格式同 ParseRate，"-1" 表示无限速。
+// burst: 独立令牌桶的突发容量，<= 0 时默认为每秒速率对应的字节数。
+// 速率字符串无效时返回错误。
+func NewRateLimitedReaderFromString(r io.Reader, rateStr string, burst int, ctx context.Context) (*RateLimitedReader, error) {
+	limit, err := ParseRate(rateStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate %q: %w", rateStr, err)
+	}
+	return NewRateLimitedReader(r, limit, burst, ctx), nil
+}
 
-		globalLimitActiveAtCreation:     globalLimitActive,
-		individualLimitActiveAtCreation: individualLimitActive,
-		bypassLimiting:                  bypass,
+// defaultBurst 返回与每秒速率相当的突发容量，至少为 1 字节。
+func defaultBurst(limit rate.Limit) int {
+	if float64(limit) >= float64(math.MaxInt32) {
+		return math.MaxInt32
 	}
+	if limit < 1 {
+		return 1
+	}
+	return int(limit)
 }
 
 // Read 实现 io.Reader 接口。
-// 在读取数据之前，根据缓存的状态决定是否需要向限速器申请许可。
+// 读取完成后根据缓存的状态按实际读取的字节数向限速器计费，必要时等待。
 func (rlr *RateLimitedReader) Read(p []byte) (n int, err error) {
 	bytesToRequest := len(p)
 	if bytesToRequest == 0 {
@@ -108,38 +299,83 @@ func (rlr *RateLimitedReader) Read(p []byte) (n int, err error) {
 	// 首先检查缓存的 bypassLimiting 状态。
 	// 如果 bypassLimiting 为 true，意味着创建时全局和独立限速都无限速，
 	// 且我们假设全局限速运行时不变，所以可以完全透穿。
-	if rlr.bypassLimiting {
-		return rlr.r.Read(p) // 完全跳过限速逻辑，直接透穿
+	if rlr.bypassLimiting.Load() {
+		n, err = rlr.r.Read(p) // 完全跳过限速逻辑，直接透穿
+		rlr.bytesRead.Add(int64(n))
+		return n, err
 	}
 
-	// 如果执行到这里，说明创建时至少有一个限速器是激活的。
-	// 我们根据创建时缓存的状态来决定是否需要等待。
+	if err := rlr.ctx.Err(); err != nil {
+		return 0, err
+	}
+	// 单次读取不超过限速器的突发容量，保证读到的字节都能被计费
+	if chunk := rlr.maxChunk(); chunk > 0 && bytesToRequest > chunk {
+		p = p[:chunk]
+	}
 
-	// 如果创建时全局限速是激活的，则应用全局限速。
-	// 需要获取当前的全局限速器实例来调用 WaitN。
-	// 在运行时不变动的假设下，globalLimiter.Load() 将始终返回同一个 rate.Limiter 实例的指针。
-	if rlr.globalLimitActiveAtCreation {
-		// 加载当前的全局限速器实例 (使用 atomic.Load)
-		currentGlobalLimiter := globalLimiter.Load()
-		// WaitN 会阻塞直到有令牌或 Context 被取消
-		if err := currentGlobalLimiter.WaitN(rlr.ctx, bytesToRequest); err != nil {
-			return 0, err
+	// 向底层的 Reader 读取数据
+	n, err = rlr.r.Read(p)
+	rlr.bytesRead.Add(int64(n))
+
+	// 按实际读取的字节数计费，而不是按 len(p) 预先申请，短读时不会多扣令牌
+	if n > 0 {
+		if werr := rlr.charge(n); werr != nil {
+			return n, werr
 		}
 	}
+	return n, err
+}
 
-	// 如果创建时独立限速是激活的，则应用独立限速。
-	if rlr.individualLimitActiveAtCreation {
-		// rlr.limiter 是该 reader 的独立限速器实例，直接调用 WaitN
-		if err := rlr.limiter.WaitN(rlr.ctx, bytesToRequest); err != nil {
-			// WaitN 内部会检查 Context，即使在全局等待时 Context 已取消，这里也会正确处理
-			return 0, err
+// maxWriteToBuffer 是 WriteTo 中间缓冲区的上限，避免为很大的突发容量分配过大的缓冲区
+const maxWriteToBuffer = 1 << 20
+
+// WriteTo 实现 io.WriterTo 接口，io.Copy 会优先使用它。
+// 中间缓冲区的大小与突发容量一致 (无限速时为 32KiB，最多 1MiB)，每次读取一个突发容量后写出，
+// 使输出的节奏更平滑。Context 被取消时立即返回已写出的字节数和 ctx.Err()。
+func (rlr *RateLimitedReader) WriteTo(w io.Writer) (written int64, err error) {
+	size := rlr.maxChunk()
+	if size <= 0 {
+		size = 32 * 1024
+	}
+	buf := make([]byte, min(size, maxWriteToBuffer))
+	for {
+		nr, er := rlr.Read(buf)
+		if nr > 0 {
+			nw, ew := w.Write(buf[:nr])
+			written += int64(nw)
+			if ew != nil {
+				return written, ew
+			}
+			if nw != nr {
+				return written, io.ErrShortWrite
+			}
+		}
+		if er != nil {
+			if er == io.EOF {
+				return written, nil
+			}
+			return written, er
 		}
 	}
+}
 
-	// 向底层的 Reader 读取数据
-	n, err = rlr.r.Read(p)
+// BytesRead 返回此读取器累计读取的字节数，可以与 Read 并发调用，
+// 例如定期采样以计算单个连接的下载速度。
+func (rlr *RateLimitedReader) BytesRead() int64 {
+	return rlr.bytesRead.Load()
+}
 
-	return n, err
+// SetContext 设置等待限速许可时使用的 Context，nil 视为 context.Background()。
+// 不能与 Read 并发调用，通常在开始读取之前调用。
+func (rlr *RateLimitedReader) SetContext(ctx context.Context) {
+	rlr.setContext(ctx)
+}
+
+// SetLimit 修改此读取器的独立速率限制，正在进行的读取会在下一次 Read 时使用新的速率。
+// 将 limit 设置为 <= 0 或 rate.Inf 将禁用独立限速；全局限速不受影响。
+// 可以与 Read 并发调用。
+func (rlr *RateLimitedReader) SetLimit(limit rate.Limit, burst int) {
+	rlr.setLimit(limit, burst)
 }
 
 // Close 实现 io.Closer 接口，转发 Close 调用给底层 Reader。
@@ -163,6 +399,7 @@ var (
 		"kbps": 1000.0 / 8.0,
 		"mbps": 1000.0 * 1000.0 / 8.0,
 		"gbps": 1000.0 * 1000.0 * 1000.0 / 8.0,
+		"tbps": 1000.0 * 1000.0 * 1000.0 * 1000.0 / 8.0,
 
 		// 字节单位 (1024-based)
 		"b":       1.0, // 假设是 B/s (Bytes/s)
@@ -188,6 +425,63 @@ var (
 		"gb/s":        1024.0 * 1024.0 * 1024.0,
 		"gigabyte":    1024.0 * 1024.0 * 1024.0,
 		"gigabytes/s": 1024.0 * 1024.0 * 1024.0,
+
+		"t":           1024.0 * 1024.0 * 1024.0 * 1024.0, // 缩写 TB/s
+		"tb":          1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"tb/s":        1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"terabyte":    1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"terabytes/s": 1024.0 * 1024.0 * 1024.0 * 1024.0,
+
+		"p":           1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0, // 缩写 PB/s
+		"pb":          1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"pb/s":        1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"petabyte":    1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"petabytes/s": 1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+
+		// IEC 二进制单位，始终为 1024-based
+		"kib":   1024.0,
+		"kib/s": 1024.0,
+		"mib":   1024.0 * 1024.0,
+		"mib/s": 1024.0 * 1024.0,
+		"gib":   1024.0 * 1024.0 * 1024.0,
+		"gib/s": 1024.0 * 1024.0 * 1024.0,
+		"tib":   1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"tib/s": 1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"pib":   1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+		"pib/s": 1024.0 * 1024.0 * 1024.0 * 1024.0 * 1024.0,
+	}
+
+	// siUnitToBytesPerSec 在 ParseRateSI 中覆盖 unitToBytesPerSec 的十进制字节单位 (1000-based)
+	siUnitToBytesPerSec = map[string]float64{
+		"k":           1e3,
+		"kb":          1e3,
+		"kb/s":        1e3,
+		"kilobyte":    1e3,
+		"kilobytes/s": 1e3,
+
+		"m":           1e6,
+		"mb":          1e6,
+		"mb/s":        1e6,
+		"megabyte":    1e6,
+		"megabytes/s": 1e6,
+
+		"g":           1e9,
+		"gb":          1e9,
+		"gb/s":        1e9,
+		"gigabyte":    1e9,
+		"gigabytes/s": 1e9,
+
+		"t":           1e12,
+		"tb":          1e12,
+		"tb/s":        1e12,
+		"terabyte":    1e12,
+		"terabytes/s": 1e12,
+
+		"p":           1e15,
+		"pb":          1e15,
+		"pb/s":        1e15,
+		"petabyte":    1e15,
+		"petabytes/s": 1e15,
 	}
 )
 
@@ -203,11 +497,24 @@ func (e *UnDefiendRateStringErr) Error() string {
 	return e.s
 }
 
-// ParseRate 解析人类可读的速度字符串 (例如, "100kbps", "1.5MB/s", "5000")。
+// ParseRate 解析人类可读的速度字符串 (例如, "100kbps", "1.5MB/s", "1TiB/s", "5000")。
 // 返回速率，单位是每秒字节数 (rate.Limit)。
+// KB/MB/GB/TB/PB 与 KiB/MiB/GiB/TiB/PiB 均按 1024 换算，比特单位 (bps) 按 1000 换算；
+// 需要按 1000 换算十进制字节单位时使用 ParseRateSI。
 // 如果 rateStr 为 "-1" (不区分大小写，忽略空格)，则返回 rate.Inf 表示无限速。
 // 如果解析结果为非正数 (且不是 "-1")，则返回错误。
 func ParseRate(rateStr string) (rate.Limit, error) {
+	return parseRate(rateStr, false)
+}
+
+// ParseRateSI 与 ParseRate 相同，但 KB/MB/GB/TB/PB 按 SI 标准以 1000 换算，
+// 例如 "1MB/s" 为 1000000 B/s。KiB 等 IEC 单位仍按 1024 换算。
+func ParseRateSI(rateStr string) (rate.Limit, error) {
+	return parseRate(rateStr, true)
+}
+
+// parseRate 是 ParseRate 与 ParseRateSI 的共同实现，si 为 true 时十进制字节单位按 1000 换算。
+func parseRate(rateStr string, si bool) (rate.Limit, error) {
 	rateStr = strings.TrimSpace(rateStr)
 
 	// 特殊处理无限速字符串
@@ -244,6 +551,11 @@ func ParseRate(rateStr string) (rate.Limit, error) {
 
 	// 查找单位对应的乘数
 	multiplier, ok := unitToBytesPerSec[unitStr]
+	if si {
+		if m, isSI := siUnitToBytesPerSec[unitStr]; isSI {
+			multiplier = m
+		}
+	}
 	if !ok {
 		return 0, fmt.Errorf("unknown or unsupported rate unit: '%s' in '%s'", match[2], rateStr)
 	}
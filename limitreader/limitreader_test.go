@@ -0,0 +1,482 @@
+package limitreader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// chunkReader 每次最多返回 size 字节，模拟以小块返回数据的网络流
+type chunkReader struct {
+	remaining int
+	size      int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		return 0, io.EOF
+	}
+	n := min(len(p), r.size, r.remaining)
+	clear(p[:n])
+	r.remaining -= n
+	return n, nil
+}
+
+// BenchmarkShortReadThroughput 以 32KiB 缓冲区读取每次只返回 4KiB 的流,
+// rate-ratio 为实际吞吐与配置速率之比, 按实际读取字节计费时应接近 1.
+func BenchmarkShortReadThroughput(b *testing.B) {
+	const (
+		limit = 1 << 20 // 1 MiB/s
+		burst = 32 << 10
+		total = 256 << 10
+	)
+	buf := make([]byte, 32<<10)
+	var elapsed time.Duration
+	for i := 0; i < b.N; i++ {
+		rlr := NewRateLimitedReader(&chunkReader{remaining: total, size: 4 << 10}, rate.Limit(limit), burst, context.Background())
+		start := time.Now()
+		for {
+			if _, err := rlr.Read(buf); err != nil {
+				break
+			}
+		}
+		elapsed += time.Since(start)
+	}
+	// 初始的突发容量不计入限速时间
+	effective := float64(b.N) * float64(total-burst) / elapsed.Seconds()
+	b.ReportMetric(effective/limit, "rate-ratio")
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in   string
+		si   bool
+		want rate.Limit
+		ok   bool
+	}{
+		{"1TiB/s", false, 1 << 40, true},
+		{"1TiB/s", true, 1 << 40, true}, // IEC 单位不受 SI 影响
+		{"2 Gbps", false, 250_000_000, true},
+		{"1MB/s", false, 1 << 20, true},
+		{"1MB/s", true, 1_000_000, true},
+		{"1.5KiB", true, 1536, true},
+		{"3PiB", false, 3 << 50, true},
+		{"2pb", true, 2e15, true},
+		{"5000", false, 5000, true}, // 无单位按 B/s
+		{"1.5", false, 1.5, true},
+		{"-1", false, rate.Inf, true},
+		{"0", false, 0, false},
+		{"", false, 0, false},
+		{"1.2.3", false, 0, false},
+		{"10 furlongs", false, 0, false},
+		{"1GB", false, 1 << 30, true},
+		{"1 gb/s", true, 1e9, true},
+		{"1 TB/s", true, 1e12, true},
+		{"2MiB/s", true, 2 << 20, true},
+		{"1gib", false, 1 << 30, true},
+		{"8kbps", true, 1000, true}, // 比特单位始终按 1000 换算
+		{"8Mbps", false, 1_000_000, true},
+		{"1kilobytes/s", true, 1000, true},
+		{"1 XiB", true, 0, false},
+		{"-5MB", false, 0, false},
+		{"0.0", true, 0, false},
+		{"0", true, 0, false},
+		{"1e3", false, 0, false},
+	}
+	for _, tt := range tests {
+		parse := ParseRate
+		if tt.si {
+			parse = ParseRateSI
+		}
+		got, err := parse(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("parse(%q, si=%v) error = %v, want ok=%v", tt.in, tt.si, err, tt.ok)
+			continue
+		}
+		if tt.ok && got != tt.want {
+			t.Errorf("parse(%q, si=%v) = %v, want %v", tt.in, tt.si, got, tt.want)
+		}
+	}
+}
+
+// TestParseRateZero 测试 "0" 返回 UnDefiendRateStringErr, 以便调用方与其他格式错误区分
+func TestParseRateZero(t *testing.T) {
+	for _, parse := range []func(string) (rate.Limit, error){ParseRate, ParseRateSI} {
+		var undefined *UnDefiendRateStringErr
+		if _, err := parse(" 0 "); !errors.As(err, &undefined) {
+			t.Errorf("got %v, want UnDefiendRateStringErr", err)
+		}
+		if _, err := parse("0.0"); err == nil || errors.As(err, &undefined) {
+			t.Errorf(`"0.0": got %v, want a non-positive rate error`, err)
+		}
+	}
+}
+
+// TestZeroBurstMakesProgress 测试 burst 为 0 时使用默认突发容量, 读取仍能推进
+func TestZeroBurstMakesProgress(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	rlr := NewRateLimitedReader(&chunkReader{remaining: 8 << 10, size: 4 << 10}, 64<<10, 0, ctx)
+	n, err := io.Copy(io.Discard, rlr)
+	if err != nil || n != 8<<10 {
+		t.Fatalf("copied %d bytes, err = %v", n, err)
+	}
+}
+
+// TestWriterToSourceIsThrottled 测试包装实现了 io.WriterTo 的 *bytes.Reader 后，
+// 无论 io.Copy 走 WriteTo 还是目标的 ReadFrom 快速路径，都不会绕过限速
+func TestWriterToSourceIsThrottled(t *testing.T) {
+	const (
+		limit = 128 << 10
+		burst = 16 << 10
+		size  = burst + 32<<10 // 超出突发容量的部分至少需要 250ms
+	)
+	minElapsed := 200 * time.Millisecond
+
+	cases := []struct {
+		name string
+		copy func(rlr *RateLimitedReader) (int64, error)
+	}{
+		{"WriteTo", func(rlr *RateLimitedReader) (int64, error) {
+			return io.Copy(struct{ io.Writer }{io.Discard}, rlr)
+		}},
+		{"ReadFrom", func(rlr *RateLimitedReader) (int64, error) {
+			return io.Copy(new(bytes.Buffer), struct{ io.Reader }{rlr})
+		}},
+	}
+	for _, tc := range cases {
+		rlr := NewRateLimitedReaderSimple(bytes.NewReader(make([]byte, size)), limit, burst)
+		start := time.Now()
+		n, err := tc.copy(rlr)
+		elapsed := time.Since(start)
+		if err != nil || n != size {
+			t.Fatalf("%s: copied %d bytes, err = %v", tc.name, n, err)
+		}
+		if elapsed < minElapsed {
+			t.Errorf("%s: copy took %v, want at least %v", tc.name, elapsed, minElapsed)
+		}
+	}
+}
+
+// TestNewRateLimitedReaderFromString 测试由速率字符串创建读取器, 以及无效字符串返回的错误
+func TestNewRateLimitedReaderFromString(t *testing.T) {
+	rlr, err := NewRateLimitedReaderFromString(bytes.NewReader(nil), "64KiB/s", 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rlr.limiter.Limit(); got != 64<<10 {
+		t.Errorf("limit = %v, want %v", got, 64<<10)
+	}
+	if got := rlr.limiter.Burst(); got != 64<<10 {
+		t.Errorf("burst = %d, want the per-second rate %d", got, 64<<10)
+	}
+
+	rlr, err = NewRateLimitedReaderFromString(bytes.NewReader(nil), "-1", 0, nil)
+	if err != nil || !rlr.bypassLimiting.Load() {
+		t.Errorf(`"-1": err = %v, bypass = %v, want an unlimited reader`, err, rlr.bypassLimiting.Load())
+	}
+
+	var undefined *UnDefiendRateStringErr
+	if _, err := NewRateLimitedReaderFromString(bytes.NewReader(nil), "0", 0, nil); !errors.As(err, &undefined) {
+		t.Errorf(`"0": got %v, want UnDefiendRateStringErr`, err)
+	}
+	if _, err := NewRateLimitedReaderFromString(bytes.NewReader(nil), "fast", 0, nil); err == nil {
+		t.Error(`"fast": expected error`)
+	}
+}
+
+// chunkRecorder 记录写入底层 Writer 的最大单次写入长度
+type chunkRecorder struct {
+	total, max int
+}
+
+func (w *chunkRecorder) Write(p []byte) (int, error) {
+	w.total += len(p)
+	w.max = max(w.max, len(p))
+	return len(p), nil
+}
+
+// TestWriterIsThrottled 测试 RateLimitedWriter 按突发容量分块写入, 吞吐不超过限速, 取消 Context 后返回 ctx.Err()
+func TestWriterIsThrottled(t *testing.T) {
+	const (
+		limit = 128 << 10
+		burst = 16 << 10
+		size  = burst + 32<<10 // 超出突发容量的部分至少需要 250ms
+	)
+	var rec chunkRecorder
+	rlw := NewRateLimitedWriter(&rec, limit, burst, context.Background())
+	start := time.Now()
+	n, err := rlw.Write(make([]byte, size))
+	elapsed := time.Since(start)
+	if err != nil || n != size || rec.total != size {
+		t.Fatalf("wrote %d (%d underlying) bytes, err = %v", n, rec.total, err)
+	}
+	if rec.max > burst {
+		t.Errorf("largest underlying write = %d, want at most the burst %d", rec.max, burst)
+	}
+	if elapsed < 200*time.Millisecond {
+		t.Errorf("write took %v, want at least 200ms", elapsed)
+	}
+
+	// 无限速时直接透传
+	rec = chunkRecorder{}
+	if n, err := NewRateLimitedWriter(&rec, rate.Inf, 0, nil).Write(make([]byte, size)); err != nil || n != size || rec.max != size {
+		t.Errorf("unlimited write: n = %d, max chunk = %d, err = %v", n, rec.max, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rlw = NewRateLimitedWriter(io.Discard, 16<<10, 4<<10, ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	n, err = rlw.Write(make([]byte, 64<<10)) // 不取消时需要约 3.75s
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if n >= 64<<10 || time.Since(start) > 2*time.Second {
+		t.Errorf("cancellation not honored promptly: wrote %d bytes in %v", n, time.Since(start))
+	}
+}
+
+// TestSetLimit 测试 SetLimit 可以在读取过程中关闭或重新开启独立限速, 并可与 Read 并发调用
+func TestSetLimit(t *testing.T) {
+	const size = 256 << 10
+	rlr := NewRateLimitedReaderSimple(bytes.NewReader(make([]byte, size)), 1<<10, 1<<10)
+	rlr.SetLimit(rate.Inf, 0)
+	start := time.Now()
+	if n, err := io.Copy(io.Discard, rlr); err != nil || n != size {
+		t.Fatalf("copied %d bytes, err = %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("unlimited copy took %v", elapsed)
+	}
+
+	rlr = NewRateLimitedReaderSimple(bytes.NewReader(make([]byte, 48<<10)), rate.Inf, 0)
+	rlr.SetLimit(128<<10, 16<<10)
+	start = time.Now()
+	if n, err := io.Copy(io.Discard, rlr); err != nil || n != 48<<10 {
+		t.Fatalf("copied %d bytes, err = %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("re-limited copy took %v, want at least 200ms", elapsed)
+	}
+
+	// 读取过程中不断修改速率
+	rlr = NewRateLimitedReaderSimple(&chunkReader{remaining: 1 << 20, size: 4 << 10}, 1<<20, 0)
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for i := 0; ; i++ {
+			select {
+			case <-time.After(time.Millisecond):
+			case <-stop:
+				return
+			}
+			if i%2 == 0 {
+				rlr.SetLimit(rate.Inf, 0)
+			} else {
+				rlr.SetLimit(8<<20, 64<<10)
+			}
+		}
+	}()
+	n, err := io.Copy(io.Discard, rlr)
+	close(stop)
+	<-stopped
+	if err != nil || n != 1<<20 {
+		t.Fatalf("copied %d bytes while changing the limit, err = %v", n, err)
+	}
+}
+
+// TestShortReadsChargeActualBytes 测试短读只按实际读取的字节计费, 吞吐接近配置的速率而不是被 len(p) 拖慢
+func TestShortReadsChargeActualBytes(t *testing.T) {
+	const (
+		limit = 128 << 10
+		burst = 32 << 10
+		total = 96 << 10 // 按实际字节计费约 500ms, 按 len(p) 计费约 6s
+	)
+	rlr := NewRateLimitedReaderSimple(&chunkReader{remaining: total, size: 4 << 10}, limit, burst)
+	buf := make([]byte, burst)
+	start := time.Now()
+	var n int64
+	for {
+		nr, err := rlr.Read(buf)
+		n += int64(nr)
+		if err != nil {
+			break
+		}
+	}
+	elapsed := time.Since(start)
+	if n != total {
+		t.Fatalf("read %d bytes, want %d", n, total)
+	}
+	if elapsed < 350*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("read took %v, want about 500ms", elapsed)
+	}
+}
+
+// TestGroupSharesLimit 测试同一分组的读取器共享令牌桶, 修改分组速率立即对已创建的读取器生效
+func TestGroupSharesLimit(t *testing.T) {
+	const (
+		group = "test-shared"
+		limit = 128 << 10
+		burst = 16 << 10
+		each  = 24 << 10 // 两个读取器合计超出突发容量 32KiB, 至少需要 250ms
+	)
+	SetGroupRateLimit(group, limit, burst)
+	defer SetGroupRateLimit(group, rate.Inf, 0)
+	if GetGroupLimiter(group) != GetGroupLimiter(group) {
+		t.Fatal("GetGroupLimiter returned different limiters for the same name")
+	}
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rlr := NewRateLimitedReaderWithGroup(bytes.NewReader(make([]byte, each)), group, rate.Inf, 0, nil)
+			if n, err := io.Copy(io.Discard, rlr); err != nil || n != each {
+				t.Errorf("copied %d bytes, err = %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	// 单个读取器独占令牌桶时约 62ms, 共享时至少 250ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("group copy took %v, want at least 200ms", elapsed)
+	}
+
+	// 关闭分组限速后, 已创建的读取器不再等待
+	rlr := NewRateLimitedReaderWithGroup(bytes.NewReader(make([]byte, 1<<20)), group, rate.Inf, 0, nil)
+	SetGroupRateLimit(group, rate.Inf, 0)
+	start = time.Now()
+	if n, err := io.Copy(io.Discard, rlr); err != nil || n != 1<<20 {
+		t.Fatalf("copied %d bytes, err = %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("copy after lifting the group limit took %v", elapsed)
+	}
+}
+
+// TestBytesRead 测试 BytesRead 统计限速与透传两种路径读取的字节数, 并可与 Read 并发调用
+func TestBytesRead(t *testing.T) {
+	for _, limit := range []rate.Limit{rate.Inf, 1 << 20} {
+		rlr := NewRateLimitedReaderSimple(&chunkReader{remaining: 256 << 10, size: 4 << 10}, limit, 0)
+		stop, stopped := make(chan struct{}), make(chan struct{})
+		go func() {
+			defer close(stopped)
+			var last int64
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if n := rlr.BytesRead(); n < last {
+					t.Errorf("BytesRead went backwards: %d after %d", n, last)
+				} else {
+					last = n
+				}
+			}
+		}()
+		n, err := io.Copy(io.Discard, rlr)
+		close(stop)
+		<-stopped
+		if err != nil || n != 256<<10 || rlr.BytesRead() != n {
+			t.Errorf("limit %v: copied %d, BytesRead = %d, err = %v", limit, n, rlr.BytesRead(), err)
+		}
+	}
+}
+
+// TestReadContextCanceled 测试等待许可时取消 Context, Read 立即返回 ctx.Err(); 已取消的 Context 不再读取底层数据
+func TestReadContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	rlr := NewRateLimitedReader(bytes.NewReader(make([]byte, 64<<10)), 16<<10, 4<<10, ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	n, err := io.Copy(io.Discard, rlr) // 不取消时需要约 3.75s
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if n >= 64<<10 || time.Since(start) > 2*time.Second {
+		t.Errorf("cancellation not honored promptly: copied %d bytes in %v", n, time.Since(start))
+	}
+
+	read := rlr.BytesRead()
+	if _, err := rlr.Read(make([]byte, 1024)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Read after cancel: got %v, want context.Canceled", err)
+	}
+	if rlr.BytesRead() != read {
+		t.Error("Read after cancel consumed the underlying reader")
+	}
+}
+
+// TestSetContext 测试 SetContext 替换等待时使用的 Context, nil 视为 context.Background()
+func TestSetContext(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	rlr := NewRateLimitedReader(bytes.NewReader(make([]byte, 8<<10)), 64<<10, 4<<10, canceled)
+	if _, err := rlr.Read(make([]byte, 1024)); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+
+	rlr.SetContext(nil)
+	if n, err := io.Copy(io.Discard, rlr); err != nil || n != 8<<10 {
+		t.Fatalf("after SetContext(nil): copied %d bytes, err = %v", n, err)
+	}
+
+	rlr = NewRateLimitedReaderSimple(bytes.NewReader(make([]byte, 64<<10)), 16<<10, 4<<10)
+	ctx, cancel := context.WithCancel(context.Background())
+	rlr.SetContext(ctx)
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := io.Copy(io.Discard, rlr); !errors.Is(err, context.Canceled) {
+		t.Fatalf("after SetContext(ctx): got %v, want context.Canceled", err)
+	}
+}
+
+// TestCopyRateLimited 测试 CopyRateLimited 的吞吐不超过限速, 每次写出不超过突发容量, 并遵循 Context 取消
+func TestCopyRateLimited(t *testing.T) {
+	const (
+		limit = 128 << 10
+		burst = 16 << 10
+		size  = burst + 32<<10 // 超出突发容量的部分至少需要 250ms
+	)
+	var rec chunkRecorder
+	start := time.Now()
+	n, err := CopyRateLimited(&rec, bytes.NewReader(make([]byte, size)), limit, burst, context.Background())
+	elapsed := time.Since(start)
+	if err != nil || n != size || rec.total != size {
+		t.Fatalf("copied %d (%d written) bytes, err = %v", n, rec.total, err)
+	}
+	if rec.max > burst {
+		t.Errorf("largest write = %d, want at most the burst %d", rec.max, burst)
+	}
+	if elapsed < 200*time.Millisecond {
+		t.Errorf("copy took %v, want at least 200ms", elapsed)
+	}
+
+	// 目标实现 io.ReaderFrom 时同样受限速约束
+	start = time.Now()
+	if n, err := CopyRateLimited(new(bytes.Buffer), bytes.NewReader(make([]byte, size)), limit, burst, nil); err != nil || n != size {
+		t.Fatalf("copy to ReaderFrom: copied %d bytes, err = %v", n, err)
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("copy to ReaderFrom took %v, want at least 200ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start = time.Now()
+	n, err = CopyRateLimited(io.Discard, bytes.NewReader(make([]byte, 64<<10)), 16<<10, 4<<10, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if n >= 64<<10 || time.Since(start) > 2*time.Second {
+		t.Errorf("cancellation not honored promptly: copied %d bytes in %v", n, time.Since(start))
+	}
+}
@@ -0,0 +1,74 @@
+package limitreader
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"golang.org/x/time/rate"
+)
+
+// --- 限速写入器 RateLimitedWriter ---
+
+// RateLimitedWriter 包装一个 io.Writer，并应用速率限制。
+// 与 RateLimitedReader 相同，它同时受自身独立限速器和全局限速器的约束。
+type RateLimitedWriter struct {
+	w io.Writer // 原始写入器 (如: 上传到后端的连接)
+	throttle
+}
+
+// NewRateLimitedWriter 创建一个新的 RateLimitedWriter。
+// 参数含义与 NewRateLimitedReader 相同，将 limit 设置为 <= 0 或 rate.Inf 将禁用此写入器的独立限速。
+// !! 重要提示: 此 RateLimitedWriter 的全局限速行为将固定为创建此实例时的全局状态。
+func NewRateLimitedWriter(w io.Writer, limit rate.Limit, burst int, ctx context.Context) *RateLimitedWriter {
+	rlw := &RateLimitedWriter{w: w}
+	rlw.setup(limit, burst, ctx)
+	return rlw
+}
+
+// NewRateLimitedWriterFromString 解析速率字符串并创建 RateLimitedWriter，参数含义同 NewRateLimitedReaderFromString。
+func NewRateLimitedWriterFromString(w io.Writer, rateStr string, burst int, ctx context.Context) (*RateLimitedWriter, error) {
+	limit, err := ParseRate(rateStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rate %q: %w", rateStr, err)
+	}
+	return NewRateLimitedWriter(w, limit, burst, ctx), nil
+}
+
+// Write 实现 io.Writer 接口。
+// 每次写入底层 Writer 之前都会向限速器申请许可；p 超过突发容量时按突发容量分块写入。
+func (rlw *RateLimitedWriter) Write(p []byte) (n int, err error) {
+	if rlw.bypassLimiting.Load() || len(p) == 0 {
+		return rlw.w.Write(p) // 完全跳过限速逻辑，直接透穿
+	}
+
+	chunk := rlw.maxChunk()
+	for n < len(p) {
+		end := len(p)
+		if chunk > 0 && end-n > chunk {
+			end = n + chunk
+		}
+		if err := rlw.wait(end - n); err != nil {
+			return n, err
+		}
+		written, err := rlw.w.Write(p[n:end])
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// SetLimit 修改此写入器的独立速率限制，语义同 RateLimitedReader.SetLimit。
+func (rlw *RateLimitedWriter) SetLimit(limit rate.Limit, burst int) {
+	rlw.setLimit(limit, burst)
+}
+
+// Close 实现 io.Closer 接口，转发 Close 调用给底层 Writer。
+func (rlw *RateLimitedWriter) Close() error {
+	if closer, ok := rlw.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package log
+
+import "io"
+
+// ANSI 颜色转义序列
+const (
+	colorReset  = "\x1b[0m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorCyan   = "\x1b[36m"
+	colorGray   = "\x1b[90m"
+)
+
+// color 返回该等级对应的 ANSI 颜色
+func (lv Level) color() string {
+	switch {
+	case lv >= LevelError:
+		return colorRed
+	case lv >= LevelWarn:
+		return colorYellow
+	case lv >= LevelInfo:
+		return colorCyan
+	}
+	return colorGray
+}
+
+// appendColored 追加 s, color 非空时用该颜色包裹
+func appendColored(buf *[]byte, s string, color string) {
+	if s == "" {
+		return
+	}
+	if color == "" {
+		*buf = append(*buf, s...)
+		return
+	}
+	*buf = append(*buf, color...)
+	*buf = append(*buf, s...)
+	*buf = append(*buf, colorReset...)
+}
+
+// SetColor 设置是否为日志等级 (设置 Llevel 时) 或前缀着色.
+// 只有当所有输出都是终端时才会真正输出颜色, 写入文件、管道或设置了 Handler 时自动关闭.
+func (l *Logger) SetColor(enable bool) {
+	l = l.root()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	l.color.Store(enable)
+	l.updateColor()
+}
+
+// updateColor 根据当前输出重新判断是否输出颜色, 需持有 outMu
+func (l *Logger) updateColor() {
+	active := l.color.Load() && l.handler == nil && isTerminal(l.out)
+	for _, w := range l.extraOuts {
+		active = active && isTerminal(w)
+	}
+	l.colorActive.Store(active)
+}
+
+// isTerminal 判断 w 是否为终端: w 需提供文件描述符 (如 *os.File), 且该描述符能取得终端属性.
+// /dev/null 等字符设备不是终端, 不能只看文件模式.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	return ok && isTerminalFd(f.Fd())
+}
@@ -0,0 +1,77 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestColor 测试强制启用颜色时等级与前缀带有 ANSI 转义序列, 输出不是终端时 SetColor 不输出颜色.
+func TestColor(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "app: ", Llevel)
+	l.SetColor(true)
+	l.Error("plain")
+	if got := buf.String(); strings.Contains(got, "\x1b[") {
+		t.Fatalf("colored output on a non-terminal writer: %q", got)
+	}
+
+	// 输出不是终端, 直接打开颜色开关以检查着色结果
+	l.colorActive.Store(true)
+	for _, tc := range []struct {
+		log  func(...any)
+		want string
+	}{
+		{l.Debug, "app: " + colorGray + "[DEBUG]" + colorReset + " m\n"},
+		{l.Info, "app: " + colorCyan + "[INFO]" + colorReset + " m\n"},
+		{l.Warn, "app: " + colorYellow + "[WARN]" + colorReset + " m\n"},
+		{l.Error, "app: " + colorRed + "[ERROR]" + colorReset + " m\n"},
+	} {
+		buf.Reset()
+		tc.log("m")
+		if got := buf.String(); got != tc.want {
+			t.Errorf("got %q, want %q", got, tc.want)
+		}
+	}
+
+	// 未设置 Llevel 时为前缀着色
+	buf.Reset()
+	l.SetFlags(0)
+	l.Warn("m")
+	if got, want := buf.String(), colorYellow+"app: "+colorReset+"m\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// 更换输出时重新判断
+	l.SetOutput(&buf)
+	buf.Reset()
+	l.Warn("m")
+	if got := buf.String(); strings.Contains(got, "\x1b[") {
+		t.Fatalf("colored output after SetOutput: %q", got)
+	}
+}
+
+// TestIsTerminal 测试普通文件与 /dev/null 这样的字符设备都不被当作终端.
+func TestIsTerminal(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if isTerminal(f) {
+		t.Error("regular file reported as a terminal")
+	}
+	if isTerminal(&bytes.Buffer{}) {
+		t.Error("bytes.Buffer reported as a terminal")
+	}
+
+	null, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skip(err)
+	}
+	defer null.Close()
+	if isTerminal(null) {
+		t.Errorf("%s reported as a terminal", os.DevNull)
+	}
+}
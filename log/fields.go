@@ -0,0 +1,63 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// badKey 用于 With 中缺少键的值, 与 log/slog 的约定一致
+const badKey = "!BADKEY"
+
+// trimNewline 去掉末尾的一个换行, 以便在消息之后追加键值对
+func trimNewline(b []byte) []byte {
+	if len(b) > 0 && b[len(b)-1] == '\n' {
+		return b[:len(b)-1]
+	}
+	return b
+}
+
+// fieldKey 返回第 i 对键值的键和值. 落单的最后一个元素视为缺少键的值.
+func fieldKey(fields []any, i int) (key string, val any, ok bool) {
+	if i+1 >= len(fields) {
+		return badKey, fields[i], false
+	}
+	if k, isString := fields[i].(string); isString {
+		return k, fields[i+1], true
+	}
+	return fmt.Sprint(fields[i]), fields[i+1], true
+}
+
+// appendFields 以 " key=value" 的形式追加键值对, 含空白、引号或 '=' 的值会加引号
+func appendFields(b []byte, fields []any) []byte {
+	for i := 0; i < len(fields); i += 2 {
+		key, val, _ := fieldKey(fields, i)
+		b = append(b, ' ')
+		b = append(b, key...)
+		b = append(b, '=')
+		b = appendTextValue(b, val)
+	}
+	return b
+}
+
+func appendTextValue(b []byte, v any) []byte {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case error:
+		s = v.Error()
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case bool:
+		return strconv.AppendBool(b, v)
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\r\n\"=") {
+		return strconv.AppendQuote(b, s)
+	}
+	return append(b, s...)
+}
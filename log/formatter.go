@@ -0,0 +1,177 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// Formatter 自定义一条日志的完整格式.
+// 设置 Formatter 后 formatHeader 不再生效, 由 Format 负责把时间、等级、前缀、调用位置和消息写入 buf.
+// level 为这条日志的等级, 是否输出由 Formatter 决定, 不受 Llevel 影响.
+// msg 为消息正文, 末尾的换行仍由 Logger 统一补齐.
+type Formatter interface {
+	Format(buf *[]byte, t time.Time, level Level, prefix string, flag int, file string, line int, msg []byte)
+}
+
+// FieldFormatter 是可以自行输出 With 附加键值对的 Formatter.
+// 未实现此接口的 Formatter 收到的 msg 已包含 key=value 形式的键值对.
+type FieldFormatter interface {
+	Formatter
+	FormatFields(buf *[]byte, t time.Time, level Level, prefix string, flag int, file string, line int, msg []byte, fields []any)
+}
+
+// JSONFormatter 将每条日志输出为一行 JSON 对象, 例如
+//
+//	{"time":"2009-01-23T01:23:23.123123Z","level":"INFO","prefix":"app: ","file":"d.go:23","msg":"hello"}
+//
+// level 总是输出; prefix 为空时省略; 只有设置了 Lshortfile 或 Llongfile 时才输出 file.
+// With 附加的键值对作为同级字段输出在 msg 之后.
+type JSONFormatter struct{}
+
+// Format 实现 Formatter 接口.
+func (f JSONFormatter) Format(buf *[]byte, t time.Time, level Level, prefix string, flag int, file string, line int, msg []byte) {
+	f.FormatFields(buf, t, level, prefix, flag, file, line, msg, nil)
+}
+
+// FormatFields 实现 FieldFormatter 接口.
+func (JSONFormatter) FormatFields(buf *[]byte, t time.Time, level Level, prefix string, flag int, file string, line int, msg []byte, fields []any) {
+	*buf = append(*buf, `{"time":"`...)
+	*buf = t.AppendFormat(*buf, time.RFC3339Nano)
+	*buf = append(*buf, `","level":"`...)
+	*buf = append(*buf, level.String()...)
+	*buf = append(*buf, '"')
+	if prefix != "" {
+		*buf = append(*buf, `,"prefix":`...)
+		*buf = appendJSONString(*buf, prefix)
+	}
+	if flag&(Lshortfile|Llongfile) != 0 {
+		if flag&Lshortfile != 0 {
+			for i := len(file) - 1; i > 0; i-- {
+				if file[i] == '/' {
+					file = file[i+1:]
+					break
+				}
+			}
+		}
+		*buf = append(*buf, `,"file":`...)
+		*buf = appendJSONString(*buf, file)
+		*buf = (*buf)[:len(*buf)-1] // 去掉右引号, 补上行号
+		*buf = append(*buf, ':')
+		itoa(buf, line, -1)
+		*buf = append(*buf, '"')
+	}
+	// 消息末尾的换行属于行分隔符, 不计入 msg 字段
+	for len(msg) > 0 && msg[len(msg)-1] == '\n' {
+		msg = msg[:len(msg)-1]
+	}
+	*buf = append(*buf, `,"msg":`...)
+	*buf = appendJSONString(*buf, msg)
+	for i := 0; i < len(fields); i += 2 {
+		key, val, _ := fieldKey(fields, i)
+		*buf = append(*buf, ',')
+		*buf = appendJSONString(*buf, key)
+		*buf = append(*buf, ':')
+		*buf = appendJSONValue(*buf, val)
+	}
+	*buf = append(*buf, '}')
+}
+
+// appendJSONValue 将 v 作为 JSON 值追加到 buf, 数字和布尔值原样输出, 其余类型按字符串输出.
+func appendJSONValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, "null"...)
+	case string:
+		return appendJSONString(buf, v)
+	case bool:
+		return strconv.AppendBool(buf, v)
+	case int:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(buf, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(buf, v, 10)
+	case uint:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(buf, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(buf, v, 10)
+	case float32:
+		return appendJSONFloat(buf, float64(v), 32)
+	case float64:
+		return appendJSONFloat(buf, v, 64)
+	case error:
+		return appendJSONString(buf, v.Error())
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	case time.Time:
+		buf = append(buf, '"')
+		buf = v.AppendFormat(buf, time.RFC3339Nano)
+		return append(buf, '"')
+	}
+	return appendJSONString(buf, fmt.Sprint(v))
+}
+
+// appendJSONFloat 输出浮点数, NaN 与 ±Inf 不是合法的 JSON 数字, 以字符串输出.
+func appendJSONFloat(buf []byte, f float64, bitSize int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return appendJSONString(buf, strconv.FormatFloat(f, 'g', -1, bitSize))
+	}
+	return strconv.AppendFloat(buf, f, 'g', -1, bitSize)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 将 s 作为带引号的 JSON 字符串追加到 buf, 非法的 UTF-8 会被替换为 U+FFFD.
+func appendJSONString[T string | []byte](buf []byte, s T) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(string(s[i:]))
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
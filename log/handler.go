@@ -0,0 +1,180 @@
+package log
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// Level 表示日志的严重程度, 数值越大越严重.
+type Level int
+
+const (
+	LevelDebug Level = iota // 调试日志
+	LevelInfo               // 信息日志, Print 系列函数使用此等级
+	LevelWarn               // 警告日志
+	LevelError              // 错误日志, Fatal 与 Panic 系列函数使用此等级
+)
+
+// String 返回等级的大写名称, 例如 "INFO".
+func (lv Level) String() string {
+	switch lv {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	}
+	return "LEVEL(" + strconv.Itoa(int(lv)) + ")"
+}
+
+// Handler 决定一条已格式化日志的去向.
+// buf 在 Handle 返回后会被回收复用, 如需保留必须自行拷贝.
+type Handler interface {
+	Handle(level Level, buf []byte) error
+}
+
+// HandlerFunc 允许将普通函数用作 Handler.
+type HandlerFunc func(level Level, buf []byte) error
+
+// Handle 调用 f(level, buf).
+func (f HandlerFunc) Handle(level Level, buf []byte) error {
+	return f(level, buf)
+}
+
+// WriterHandler 返回一个将所有日志写入 w 的 Handler, 即 SetOutput 的默认行为.
+func WriterHandler(w io.Writer) Handler {
+	return HandlerFunc(func(_ Level, buf []byte) error {
+		_, err := w.Write(buf)
+		return err
+	})
+}
+
+// LevelFilterHandler 返回一个仅将等级不低于 min 的日志交给 h 的 Handler.
+func LevelFilterHandler(min Level, h Handler) Handler {
+	return HandlerFunc(func(level Level, buf []byte) error {
+		if level < min {
+			return nil
+		}
+		return h.Handle(level, buf)
+	})
+}
+
+// MultiHandler 返回一个将日志依次分发给所有 handlers 的 Handler.
+// 某个 handler 出错不会影响其余 handler, 所有错误会被合并返回.
+func MultiHandler(handlers ...Handler) Handler {
+	hs := make([]Handler, len(handlers))
+	copy(hs, handlers)
+	return HandlerFunc(func(level Level, buf []byte) error {
+		var errs []error
+		for _, h := range hs {
+			if err := h.Handle(level, buf); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	})
+}
+
+// ErrHandlerClosed 表示向已关闭的 QueuedHandler 写入日志.
+var ErrHandlerClosed = errors.New("log: handler closed")
+
+// QueuedHandler 为下游 Handler 配备独立的协程和有界队列.
+// 将多个慢速 sink 分别包装后交给 MultiHandler, 某个 sink 变慢时只会积压它自己的队列,
+// 不会拖慢其他 sink.
+type QueuedHandler struct {
+	h       Handler
+	queue   chan logEntry
+	block   bool // 队列已满时阻塞等待, 否则丢弃
+	dropped atomic.Int64
+
+	mu        sync.RWMutex // 保证 Close 之后不会再有日志入队
+	closed    bool
+	closeChan chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewQueuedHandler 创建一个容量为 size 的 QueuedHandler 并启动其写入协程.
+// block 为 true 时队列已满会阻塞调用方, 为 false 时丢弃新日志并计入 Dropped.
+// 下游 h 返回的错误会被忽略, 因为此时原调用早已返回.
+func NewQueuedHandler(h Handler, size int, block bool) *QueuedHandler {
+	q := &QueuedHandler{
+		h:         h,
+		queue:     make(chan logEntry, size),
+		block:     block,
+		closeChan: make(chan struct{}),
+	}
+	q.wg.Add(1)
+	go q.process()
+	return q
+}
+
+func (q *QueuedHandler) process() {
+	defer q.wg.Done()
+	for {
+		select {
+		case entry := <-q.queue:
+			q.h.Handle(entry.level, *entry.buf)
+			putBuffer(entry.buf)
+		case <-q.closeChan:
+			for {
+				select {
+				case entry := <-q.queue:
+					q.h.Handle(entry.level, *entry.buf)
+					putBuffer(entry.buf)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Handle 拷贝 buf 并放入队列, 由写入协程交给下游 Handler.
+func (q *QueuedHandler) Handle(level Level, buf []byte) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrHandlerClosed
+	}
+
+	b := getBuffer()
+	*b = append(*b, buf...)
+	entry := logEntry{level: level, buf: b}
+	if q.block {
+		q.queue <- entry
+		return nil
+	}
+	select {
+	case q.queue <- entry:
+	default:
+		q.dropped.Add(1)
+		putBuffer(b)
+	}
+	return nil
+}
+
+// Dropped 返回因队列已满而被丢弃的日志数.
+func (q *QueuedHandler) Dropped() int64 {
+	return q.dropped.Load()
+}
+
+// Close 停止接收新日志, 并等待队列中剩余的日志写完. 可重复调用.
+func (q *QueuedHandler) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	q.mu.Unlock()
+
+	close(q.closeChan)
+	q.wg.Wait()
+	return nil
+}
@@ -0,0 +1,151 @@
+package log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestHandlerRouting 测试 LevelFilterHandler 与 MultiHandler 按等级分发日志, 并合并各 handler 的错误.
+func TestHandlerRouting(t *testing.T) {
+	var all, errOnly bytes.Buffer
+	l := New(nil, "", Llevel)
+	l.SetHandler(MultiHandler(
+		WriterHandler(&all),
+		LevelFilterHandler(LevelError, WriterHandler(&errOnly)),
+	))
+
+	l.Info("started")
+	l.Error("failed")
+	if got, want := all.String(), "[INFO] started\n[ERROR] failed\n"; got != want {
+		t.Fatalf("all sink: got %q, want %q", got, want)
+	}
+	if got, want := errOnly.String(), "[ERROR] failed\n"; got != want {
+		t.Fatalf("error sink: got %q, want %q", got, want)
+	}
+
+	errA, errB := errors.New("a"), errors.New("b")
+	var reached bool
+	h := MultiHandler(
+		HandlerFunc(func(Level, []byte) error { return errA }),
+		HandlerFunc(func(Level, []byte) error { reached = true; return nil }),
+		HandlerFunc(func(Level, []byte) error { return errB }),
+	)
+	err := h.Handle(LevelInfo, []byte("x\n"))
+	if !reached {
+		t.Fatal("a failing handler stopped the rest")
+	}
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("got %v, want both errors joined", err)
+	}
+
+	// SetHandler(nil) 恢复为写入 SetOutput 设置的 Writer
+	var out bytes.Buffer
+	l.SetOutput(&out)
+	l.SetHandler(HandlerFunc(func(Level, []byte) error { return nil }))
+	l.SetHandler(nil)
+	l.Warn("back")
+	if got, want := out.String(), "[WARN] back\n"; got != want {
+		t.Fatalf("after SetHandler(nil): got %q, want %q", got, want)
+	}
+}
+
+// TestQueuedHandler 测试 QueuedHandler 按顺序投递、拷贝 buf、队列满时丢弃计数以及关闭后的行为.
+func TestQueuedHandler(t *testing.T) {
+	var out bytes.Buffer
+	q := NewQueuedHandler(WriterHandler(&out), 16, true)
+	l := New(nil, "", 0)
+	l.SetHandler(q)
+	for i := 0; i < 10; i++ {
+		l.Printf("line%d", i)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got, want := out.String(), numberedLines("line", 10); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if err := q.Handle(LevelInfo, []byte("late\n")); !errors.Is(err, ErrHandlerClosed) {
+		t.Fatalf("Handle after Close: got %v, want ErrHandlerClosed", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+
+	// 非阻塞模式: 下游卡住时, 超出队列容量的日志被丢弃并计数
+	w := newGateWriter()
+	q = NewQueuedHandler(WriterHandler(w), 1, false)
+	buf := []byte("a\n")
+	q.Handle(LevelInfo, buf)
+	<-w.entered
+	copy(buf, "x\n") // Handle 返回后调用方可以复用 buf
+	q.Handle(LevelInfo, []byte("b\n"))
+	q.Handle(LevelInfo, []byte("c\n"))
+	q.Handle(LevelInfo, []byte("d\n"))
+	if d := q.Dropped(); d != 2 {
+		t.Fatalf("Dropped() = %d, want 2", d)
+	}
+	w.open()
+	q.Close()
+	if got, want := w.String(), "a\nb\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// TestJournalHandler 测试 JournalHandler 按原生协议编码优先级、附加字段与单行/多行消息.
+func TestJournalHandler(t *testing.T) {
+	if _, err := NewJournalHandler(map[string]string{"bad-name": "x"}); err == nil {
+		t.Fatal("invalid field name: want error")
+	}
+
+	addr := &net.UnixAddr{Name: filepath.Join(t.TempDir(), "journal.sock"), Net: "unixgram"}
+	server, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Skipf("unixgram not supported: %v", err)
+	}
+	defer server.Close()
+	conn, err := net.Dial("unixgram", addr.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &JournalHandler{
+		conn:   conn,
+		fields: []string{string(appendJournalField(nil, "SYSLOG_IDENTIFIER", "app"))},
+	}
+	defer h.Close()
+
+	recv := func() string {
+		buf := make([]byte, 1024)
+		server.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := server.Read(buf)
+		if err != nil {
+			t.Fatalf("read datagram: %v", err)
+		}
+		return string(buf[:n])
+	}
+
+	l := New(nil, "", 0)
+	l.SetHandler(h)
+	l.Warn("hello")
+	if got, want := recv(), "PRIORITY=4\nSYSLOG_IDENTIFIER=app\nMESSAGE=hello\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// 含换行的消息使用 "名称\n长度值\n" 的二进制格式
+	l.Error("a\nb")
+	var size [8]byte
+	binary.LittleEndian.PutUint64(size[:], 3)
+	if got, want := recv(), "PRIORITY=3\nSYSLOG_IDENTIFIER=app\nMESSAGE\n"+string(size[:])+"a\nb\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	for lv, want := range map[Level]int{LevelDebug: 7, LevelInfo: 6, LevelWarn: 4, LevelError: 3} {
+		if got := lv.syslogPriority(); got != want {
+			t.Errorf("%v.syslogPriority() = %d, want %d", lv, got, want)
+		}
+	}
+}
@@ -15,6 +15,8 @@
 package log
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -32,33 +34,74 @@ const (
 	Lshortfile                    // final file name element and line number: d.go:23. overrides Llongfile
 	LUTC                          // if Ldate or Ltime is set, use UTC rather than the local time zone
 	Lmsgprefix                    // move the "prefix" from the beginning of the line to before the message
+	Llevel                        // 在消息前加上日志等级, 例如 "[INFO] "
 	LstdFlags     = Ldate | Ltime // initial values for the standard logger
 )
 
 type Logger struct {
 	outMu       sync.Mutex
 	out         io.Writer
+	extraOuts   []io.Writer // AddOutput 追加的输出, 受 outMu 保护
 	prefix      atomic.Pointer[string]
 	flag        atomic.Int32
+	level       atomic.Int32 // 低于此等级的日志会被丢弃
+	callerSkip  atomic.Int32 // 获取调用位置时额外跳过的栈帧数
+	sampleN     atomic.Int32 // 采样间隔, 大于 1 时启用采样
+	sampleLevel atomic.Int32 // 不高于此等级的日志参与采样
+	sampleSeq   atomic.Uint64
+	suppressed  atomic.Int64 // 因采样而未输出的日志数, 记录在根 Logger 上
+	formatter   atomic.Pointer[Formatter]
+	color       atomic.Bool // SetColor 的设置
+	colorActive atomic.Bool // 实际是否输出颜色, 仅在输出为终端时生效
+	handler     Handler     // 非 nil 时取代 out 决定日志的去向, 受 outMu 保护
 	isDiscard   atomic.Bool
-	asyncWriter *asyncWriter // 新增异步写入器
+	asyncMu     sync.RWMutex // 投递日志时持有读锁, 切换异步写入器时持有写锁
+	asyncWriter *asyncWriter // 新增异步写入器, 受 asyncMu 保护
 	asyncMode   atomic.Bool  // 异步模式标志
+	asyncDrops  atomic.Int64 // 异步通道已满的次数
+	overflow    atomic.Int32 // 异步通道已满时的处理策略, 见 OverflowPolicy
+	overflowCtx atomic.Pointer[context.Context]
+
+	// 写入耗时统计, 单位为纳秒
+	writeCount   atomic.Int64
+	writeTotalNs atomic.Int64
+	writeMaxNs   atomic.Int64
+	slowWriteNs  atomic.Int64 // 超过此耗时的写入会在 stderr 输出警告, 0 表示不警告
+
+	hookMu sync.Mutex                     // 串行化 AddHook
+	hooks  atomic.Pointer[[]func([]byte)] // 写入前调用的钩子, 写时复制
+
+	base   *Logger // 由 With 创建的子 Logger 指向根 Logger, 与其共享输出和异步写入器
+	fields []any   // With 附加的键值对, 创建后不再修改
+}
+
+// logEntry 是投递给异步写入器的一条日志
+type logEntry struct {
+	level Level
+	buf   *[]byte       // 格式化后的日志内容, 由消费方负责 putBuffer
+	done  chan struct{} // 非 nil 时为 Flush 标记, 处理到此处时关闭
 }
 
 // 添加异步结构体
 type asyncWriter struct {
 	logger    *Logger
-	logChan   chan *[]byte // MODIFIED: Store pointers to byte slices
+	logChan   chan logEntry
 	wg        sync.WaitGroup
 	closeChan chan struct{}
+	stopping  chan struct{} // 开始关闭时关闭, 让阻塞在投递上的调用方放弃等待并释放读锁
+	stopOnce  sync.Once
+	exited    chan struct{} // process 退出时关闭
+	drainErr  error         // 关闭时清空通道遇到的第一个写入错误, process 退出后只读
 }
 
 // 创建异步写入器
 func newAsyncWriter(l *Logger, bufferSize int) *asyncWriter {
 	aw := &asyncWriter{
 		logger:    l,
-		logChan:   make(chan *[]byte, bufferSize), // MODIFIED: Channel of pointers
+		logChan:   make(chan logEntry, bufferSize),
 		closeChan: make(chan struct{}),
+		stopping:  make(chan struct{}),
+		exited:    make(chan struct{}),
 	}
 	aw.wg.Add(1)
 	go aw.process()
@@ -68,13 +111,18 @@ func newAsyncWriter(l *Logger, bufferSize int) *asyncWriter {
 // 异步处理协程
 func (aw *asyncWriter) process() {
 	defer aw.wg.Done()
+	defer close(aw.exited)
 	for {
 		select {
-		case entryBufPtr := <-aw.logChan: // entryBufPtr is *[]byte
-			aw.logger.outMu.Lock()
-			aw.logger.out.Write(*entryBufPtr)
-			aw.logger.outMu.Unlock()
-			putBuffer(entryBufPtr) // MODIFIED: Return buffer to pool after writing
+		case entry := <-aw.logChan:
+			if err := aw.handle(entry); err != nil {
+				select {
+				case <-aw.closeChan:
+					// Close 已在等待, 这条日志同样属于排空的一部分
+					aw.setDrainErr(err)
+				default:
+				}
+			}
 		case <-aw.closeChan:
 			// 关闭前清空通道
 			// Drain any remaining messages from the channel
@@ -82,11 +130,10 @@ func (aw *asyncWriter) process() {
 			// while we were processing the closeChan signal.
 			for {
 				select {
-				case entryBufPtr := <-aw.logChan:
-					aw.logger.outMu.Lock() // ADDED: Lock for consistency
-					aw.logger.out.Write(*entryBufPtr)
-					aw.logger.outMu.Unlock() // ADDED: Unlock
-					putBuffer(entryBufPtr)   // MODIFIED: Return buffer to pool
+				case entry := <-aw.logChan:
+					if err := aw.handle(entry); err != nil {
+						aw.setDrainErr(err)
+					}
 				default:
 					// logChan is empty, we can return
 					return
@@ -96,28 +143,209 @@ func (aw *asyncWriter) process() {
 	}
 }
 
+// stop 通知阻塞在 logChan 上的发送方放弃等待, 可重复调用
+func (aw *asyncWriter) stop() {
+	aw.stopOnce.Do(func() { close(aw.stopping) })
+}
+
+// setDrainErr 记录关闭期间遇到的第一个写入错误
+func (aw *asyncWriter) setDrainErr(err error) {
+	if aw.drainErr == nil {
+		aw.drainErr = err
+	}
+}
+
+// handle 处理通道中的一条记录
+func (aw *asyncWriter) handle(entry logEntry) error {
+	if entry.done != nil {
+		close(entry.done)
+		return nil
+	}
+	err := aw.logger.write(entry.level, *entry.buf)
+	putBuffer(entry.buf) // 写入完成后归还缓冲区
+	return err
+}
+
+// OverflowPolicy 决定异步通道已满时如何处理新日志
+type OverflowPolicy int32
+
+const (
+	OverflowFallbackSync OverflowPolicy = iota // 回退为同步写入 (默认)
+	OverflowBlock                              // 阻塞等待通道空出位置
+	OverflowDropNewest                         // 丢弃新日志
+)
+
+// SetAsyncOverflow 设置异步通道已满时的处理策略.
+// 无论哪种策略, 通道已满的次数都会计入 AsyncStats 的 dropped.
+func (l *Logger) SetAsyncOverflow(policy OverflowPolicy) {
+	l = l.root()
+	l.overflow.Store(int32(policy))
+}
+
+// SetAsyncOverflowContext 设置 OverflowBlock 策略下阻塞等待时遵循的 Context.
+// ctx 结束后, 仍在等待的日志会被丢弃, 调用返回 ctx.Err(). 传入 nil 表示无限等待.
+func (l *Logger) SetAsyncOverflowContext(ctx context.Context) {
+	l = l.root()
+	if ctx == nil {
+		l.overflowCtx.Store(nil)
+		return
+	}
+	l.overflowCtx.Store(&ctx)
+}
+
 // 启用异步模式（需在首次日志调用前设置）
 func (l *Logger) SetAsync(bufferSize int) {
+	l = l.root()
+	l.asyncMu.Lock()
+	defer l.asyncMu.Unlock()
 	if l.asyncMode.CompareAndSwap(false, true) {
 		l.asyncWriter = newAsyncWriter(l, bufferSize)
 	}
 }
 
+// ErrAsyncDisabled 表示 Logger 未启用异步模式
+var ErrAsyncDisabled = errors.New("log: async mode is not enabled")
+
+// ResizeAsyncBuffer 将异步通道的容量调整为 n, 无需重新创建 Logger.
+// 旧通道中的日志会先全部写出, 再切换到新的异步写入器, 日志顺序与内容都不会丢失;
+// 切换期间新的日志调用会短暂阻塞. 未启用异步模式时返回 ErrAsyncDisabled.
+func (l *Logger) ResizeAsyncBuffer(n int) error {
+	if n < 0 {
+		return fmt.Errorf("log: invalid async buffer size %d", n)
+	}
+	l = l.root()
+	l.asyncMu.Lock()
+	defer l.asyncMu.Unlock()
+	old := l.asyncWriter
+	if old == nil || !l.asyncMode.Load() {
+		return ErrAsyncDisabled
+	}
+	// 持有写锁时没有正在投递的日志, 清空旧通道后再替换即可保证顺序
+	close(old.closeChan)
+	old.wg.Wait()
+	l.asyncWriter = newAsyncWriter(l, n)
+	return old.drainErr
+}
+
 // 安全关闭异步写入器
+// Close 会等待通道中剩余的日志全部写出, 并返回其间遇到的第一个写入错误.
+// Close 返回后异步协程不会再访问输出目标, 可以安全地关闭底层文件.
+// 重复调用 Close 是安全的, 并返回相同的错误.
 func (l *Logger) Close() error {
-	if l.asyncMode.Load() { // Check if it was ever in async mode
-		// Attempt to set asyncMode to false. If it was already false, do nothing.
-		// This helps prevent new async dispatches if Close is called multiple times
-		// or if it was never truly async.
-		swapped := l.asyncMode.CompareAndSwap(true, false)
-		if swapped { // Only close if we were the ones to turn off async mode
-			close(l.asyncWriter.closeChan)
-			l.asyncWriter.wg.Wait()
-		}
+	aw := l.stopAsync()
+	if aw == nil {
+		return nil
+	}
+	aw.wg.Wait()
+	return aw.drainErr
+}
+
+// CloseWithTimeout 与 Close 相同, 但最多等待 d.
+// 如果输出目标卡住导致剩余日志未能在 d 内写完, 返回 context.DeadlineExceeded 并放弃等待,
+// 异步协程会在写入恢复后自行退出. 此时不能保证异步协程不再访问输出目标.
+func (l *Logger) CloseWithTimeout(d time.Duration) error {
+	aw := l.stopAsync()
+	if aw == nil {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-aw.exited:
+		aw.wg.Wait()
+		return aw.drainErr
+	case <-timer.C:
+		return context.DeadlineExceeded
+	}
+}
+
+// stopAsync 关闭异步模式并通知异步写入器退出, 返回该写入器; 从未启用异步模式时返回 nil.
+func (l *Logger) stopAsync() *asyncWriter {
+	l = l.root()
+	// 输出卡住时, OverflowBlock 下的投递和 Flush 会持有读锁阻塞在通道上,
+	// 先让它们放弃等待, 否则下面的写锁永远拿不到
+	l.asyncMu.RLock()
+	if aw := l.asyncWriter; aw != nil && l.asyncMode.Load() {
+		aw.stop()
+	}
+	l.asyncMu.RUnlock()
+	l.asyncMu.Lock()
+	defer l.asyncMu.Unlock()
+	aw := l.asyncWriter
+	// 持有写锁时不会有正在投递的日志, 此后的日志都走同步写入
+	if aw != nil && l.asyncMode.CompareAndSwap(true, false) {
+		close(aw.closeChan)
+	}
+	return aw
+}
+
+// AsyncStats 返回异步通道中当前排队的日志数、因通道已满而未能入队的累计次数,
+// 以及因采样 (SetSampler) 而未输出的日志数.
+// 未入队的日志按 SetAsyncOverflow 的策略处理, 该计数可用于评估 SetAsync 的缓冲区大小是否合适.
+func (l *Logger) AsyncStats() (queued, dropped, suppressed int64) {
+	l = l.root()
+	l.asyncMu.RLock()
+	if l.asyncMode.Load() && l.asyncWriter != nil {
+		queued = int64(len(l.asyncWriter.logChan))
+	}
+	l.asyncMu.RUnlock()
+	return queued, l.asyncDrops.Load(), l.suppressed.Load()
+}
+
+// Flush 阻塞直到调用时已进入异步通道的日志全部写入 out.
+// 未启用异步模式时为空操作. 与 Close 不同, Flush 不会停止异步写入器.
+func (l *Logger) Flush() error {
+	l = l.root()
+	l.asyncMu.RLock()
+	aw := l.asyncWriter
+	if !l.asyncMode.Load() || aw == nil {
+		l.asyncMu.RUnlock()
+		return nil
+	}
+	done := make(chan struct{})
+	// 标记排在当前所有日志之后, process 处理到它时说明之前的日志已写出
+	select {
+	case aw.logChan <- logEntry{done: done}:
+	case <-aw.stopping:
+		// 写入器正在关闭, 其退出前会清空通道
+		done = nil
+	}
+	l.asyncMu.RUnlock()
+	select {
+	case <-done:
+	case <-aw.exited:
+		// 写入器已关闭, 其退出前会清空通道
 	}
 	return nil
 }
 
+// root 返回持有输出目标的根 Logger
+func (l *Logger) root() *Logger {
+	if l.base != nil {
+		return l.base
+	}
+	return l
+}
+
+// With 返回一个附带键值对的子 Logger, kv 按 key, value 交替给出.
+// 键值对以 key=value 的形式追加在消息之后; 设置的 Formatter 实现了 FieldFormatter 时由其负责输出.
+// 子 Logger 与父 Logger 共享输出目标和异步写入器, 对输出的设置都会作用于根 Logger;
+// 前缀、标志、等级与 Formatter 在创建时从父 Logger 复制, 之后可独立修改.
+func (l *Logger) With(kv ...any) *Logger {
+	c := &Logger{base: l.root()}
+	c.SetPrefix(l.Prefix())
+	c.SetFlags(l.Flags())
+	c.SetLevel(l.Level())
+	c.callerSkip.Store(l.callerSkip.Load())
+	c.sampleN.Store(l.sampleN.Load())
+	c.sampleLevel.Store(l.sampleLevel.Load())
+	c.formatter.Store(l.formatter.Load())
+	c.fields = make([]any, 0, len(l.fields)+len(kv))
+	c.fields = append(c.fields, l.fields...)
+	c.fields = append(c.fields, kv...)
+	return c
+}
+
 func New(out io.Writer, prefix string, flag int) *Logger {
 	l := new(Logger)
 	l.SetOutput(out)
@@ -127,19 +355,168 @@ func New(out io.Writer, prefix string, flag int) *Logger {
 }
 
 func (l *Logger) SetOutput(w io.Writer) {
+	l = l.root()
 	l.outMu.Lock()
 	defer l.outMu.Unlock()
+	defer l.updateColor()
 	l.out = w
+	l.extraOuts = nil
+	l.handler = nil
 	l.isDiscard.Store(w == io.Discard)
 }
 
+// AddOutput 追加一个输出, 每条日志会依次写入 SetOutput 设置的 Writer 和所有追加的 Writer.
+// 某个输出写入失败不会影响其余输出, 所有错误会被合并返回.
+// SetOutput 会清除追加的输出; 设置了 Handler 时, 日志只交给 Handler.
+func (l *Logger) AddOutput(w io.Writer) {
+	l = l.root()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	defer l.updateColor()
+	l.extraOuts = append(l.extraOuts, w)
+	if w != io.Discard && l.handler == nil {
+		l.isDiscard.Store(false)
+	}
+}
+
+// SetHandler 设置日志处理器, 由 h 决定每条日志的去向.
+// 它是 SetOutput 的泛化: SetOutput(w) 等价于 SetHandler(WriterHandler(w)).
+// 传入 nil 则恢复为写入 SetOutput 设置的 Writer.
+func (l *Logger) SetHandler(h Handler) {
+	l = l.root()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	defer l.updateColor()
+	l.handler = h
+	if h != nil {
+		l.isDiscard.Store(false)
+	} else {
+		l.isDiscard.Store(l.out == io.Discard && len(l.extraOuts) == 0)
+	}
+}
+
+// Handler 返回当前设置的日志处理器, 未设置时返回 nil.
+func (l *Logger) Handler() Handler {
+	l = l.root()
+	l.outMu.Lock()
+	defer l.outMu.Unlock()
+	return l.handler
+}
+
+// write 将一条已格式化的日志交给 handler, 未设置 handler 时直接写入 out.
+func (l *Logger) write(level Level, b []byte) error {
+	l.runHooks(b)
+	l.outMu.Lock()
+	start := time.Now()
+	var err error
+	if l.handler != nil {
+		err = l.handler.Handle(level, b)
+	} else if len(l.extraOuts) == 0 {
+		_, err = l.out.Write(b)
+	} else {
+		err = l.writeAll(b)
+	}
+	elapsed := time.Since(start) // 单调时钟
+	l.outMu.Unlock()
+	l.recordWriteLatency(elapsed)
+	return err
+}
+
+// AddHook 注册一个钩子, 每条格式化后的日志 (含换行) 在写入前都会传给它.
+// 同步模式下钩子在调用方的协程中执行, 异步模式下在异步写入协程中执行.
+// 钩子执行时不持有输出锁; entry 在钩子返回后会被复用, 如需保留必须自行拷贝.
+// 钩子中的 panic 会被恢复, 不会影响日志写入.
+func (l *Logger) AddHook(fn func(entry []byte)) {
+	l = l.root()
+	l.hookMu.Lock()
+	defer l.hookMu.Unlock()
+	var hooks []func([]byte)
+	if old := l.hooks.Load(); old != nil {
+		hooks = append(hooks, *old...)
+	}
+	hooks = append(hooks, fn)
+	l.hooks.Store(&hooks)
+}
+
+// runHooks 依次调用所有钩子
+func (l *Logger) runHooks(b []byte) {
+	hooks := l.hooks.Load()
+	if hooks == nil {
+		return
+	}
+	for _, fn := range *hooks {
+		callHook(fn, b)
+	}
+}
+
+func callHook(fn func([]byte), b []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "log: hook panicked: %v\n", r)
+		}
+	}()
+	fn(b)
+}
+
+// writeAll 将 b 写入全部输出, 需持有 outMu
+func (l *Logger) writeAll(b []byte) error {
+	var errs []error
+	if _, err := l.out.Write(b); err != nil {
+		errs = append(errs, err)
+	}
+	for _, w := range l.extraOuts {
+		if _, err := w.Write(b); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// recordWriteLatency 记录一次写入耗时, 超过阈值时在 stderr 输出警告
+func (l *Logger) recordWriteLatency(d time.Duration) {
+	ns := int64(d)
+	l.writeCount.Add(1)
+	l.writeTotalNs.Add(ns)
+	for {
+		cur := l.writeMaxNs.Load()
+		if ns <= cur || l.writeMaxNs.CompareAndSwap(cur, ns) {
+			break
+		}
+	}
+	if slow := l.slowWriteNs.Load(); slow > 0 && ns > slow {
+		fmt.Fprintf(os.Stderr, "log: slow write took %v (threshold %v)\n", d, time.Duration(slow))
+	}
+}
+
+// WriteLatencyStats 返回自创建以来单次写入的最大耗时和平均耗时.
+// 统计覆盖同步写入以及异步写入器的写入.
+func (l *Logger) WriteLatencyStats() (max, avg time.Duration) {
+	l = l.root()
+	n := l.writeCount.Load()
+	if n == 0 {
+		return 0, 0
+	}
+	return time.Duration(l.writeMaxNs.Load()), time.Duration(l.writeTotalNs.Load() / n)
+}
+
+// SetSlowWriteThreshold 设置慢写入阈值, 单次写入耗时超过 d 时会向 stderr 输出警告.
+// d <= 0 表示关闭警告.
+func (l *Logger) SetSlowWriteThreshold(d time.Duration) {
+	l = l.root()
+	if d < 0 {
+		d = 0
+	}
+	l.slowWriteNs.Store(int64(d))
+}
+
 var std = New(os.Stderr, "", LstdFlags)
 
 func Default() *Logger { return std }
 
-func formatHeader(buf *[]byte, t time.Time, prefix string, flag int, file string, line int) {
+// color 非空时, prefix 会被包裹在该 ANSI 颜色中.
+func formatHeader(buf *[]byte, t time.Time, prefix string, flag int, file string, line int, color string) {
 	if flag&Lmsgprefix == 0 {
-		*buf = append(*buf, prefix...)
+		appendColored(buf, prefix, color)
 	}
 	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
 		if flag&LUTC != 0 {
@@ -203,7 +580,7 @@ func formatHeader(buf *[]byte, t time.Time, prefix string, flag int, file string
 		*buf = append(*buf, ": "...)
 	}
 	if flag&Lmsgprefix != 0 {
-		*buf = append(*buf, prefix...)
+		appendColored(buf, prefix, color)
 	}
 }
 
@@ -229,14 +606,28 @@ func putBuffer(p *[]byte) {
 	bufferPool.Put(p)
 }
 
-func (l *Logger) output(pc uintptr, calldepth int, appendOutput func([]byte) []byte) error {
-	if l.isDiscard.Load() {
+func (l *Logger) output(pc uintptr, calldepth int, level Level, appendOutput func([]byte) []byte) error {
+	return l.outputFields(pc, calldepth+1, level, nil, appendOutput)
+}
+
+// outputFields 与 output 相同, 但会在 With 附加的键值对之后再追加 extra.
+func (l *Logger) outputFields(pc uintptr, calldepth int, level Level, extra []any, appendOutput func([]byte) []byte) error {
+	r := l.root() // 输出目标与异步写入器归属于根 Logger
+	if r.isDiscard.Load() || level < l.Level() {
 		return nil
 	}
+	// 采样在分配缓冲区之前进行, 被跳过的日志只计数
+	if n := l.sampleN.Load(); n > 1 && level <= Level(l.sampleLevel.Load()) {
+		if l.sampleSeq.Add(1)%uint64(n) != 1 {
+			r.suppressed.Add(1)
+			return nil
+		}
+	}
 
 	var now time.Time
 	flag := l.Flags()
-	if flag&(Ldate|Ltime|Lmicroseconds) != 0 {
+	formatter := l.Formatter()
+	if formatter != nil || flag&(Ldate|Ltime|Lmicroseconds) != 0 {
 		now = time.Now() // Lshortfile, Llongfile, LUTC uses later
 		if flag&LUTC != 0 {
 			now = now.UTC()
@@ -253,7 +644,7 @@ func (l *Logger) output(pc uintptr, calldepth int, appendOutput func([]byte) []b
 		// l.outMu.Unlock() // Original log has this, but we are not holding it yet.
 		var ok bool
 		if pc == 0 {
-			_, file, line, ok = runtime.Caller(calldepth)
+			_, file, line, ok = runtime.Caller(calldepth + int(l.callerSkip.Load()))
 		} else {
 			// This path is taken by Fatal and Panic via internal.DefaultOutput,
 			// which we are not using directly here, but good to keep consistent.
@@ -270,40 +661,109 @@ func (l *Logger) output(pc uintptr, calldepth int, appendOutput func([]byte) []b
 		// l.outMu.Lock() // Re-acquire if unlocked above
 	}
 
+	fields := l.fields
+	if len(extra) > 0 {
+		fields = append(fields[:len(fields):len(fields)], extra...)
+	}
+
 	buf := getBuffer()
 	// No `defer putBuffer(buf)` here anymore. It's conditional.
 
-	formatHeader(buf, now, prefix, flag, file, line)
-	*buf = appendOutput(*buf)
+	if formatter != nil {
+		msg := getBuffer()
+		*msg = appendOutput(*msg)
+		if ff, ok := formatter.(FieldFormatter); ok {
+			ff.FormatFields(buf, now, level, prefix, flag, file, line, *msg, fields)
+		} else {
+			if len(fields) > 0 {
+				*msg = appendFields(trimNewline(*msg), fields)
+			}
+			formatter.Format(buf, now, level, prefix, flag, file, line, *msg)
+		}
+		putBuffer(msg)
+	} else {
+		// 启用颜色时, 优先为等级着色, 未输出等级时为前缀着色
+		var color, prefixColor string
+		if r.colorActive.Load() {
+			color = level.color()
+			if flag&Llevel == 0 {
+				prefixColor = color
+			}
+		}
+		formatHeader(buf, now, prefix, flag, file, line, prefixColor)
+		if flag&Llevel != 0 {
+			*buf = append(*buf, color...)
+			*buf = append(*buf, '[')
+			*buf = append(*buf, level.String()...)
+			*buf = append(*buf, ']')
+			if color != "" {
+				*buf = append(*buf, colorReset...)
+			}
+			*buf = append(*buf, ' ')
+		}
+		*buf = appendOutput(*buf)
+		if len(fields) > 0 {
+			*buf = appendFields(trimNewline(*buf), fields)
+		}
+	}
 	if len(*buf) == 0 || (*buf)[len(*buf)-1] != '\n' {
 		*buf = append(*buf, '\n')
 	}
+	return r.dispatch(level, buf)
+}
 
-	var err error
-	if l.asyncMode.Load() && l.asyncWriter != nil { // Check asyncWriter != nil for safety during setup/teardown
-		// Send the pointer to the buffer to the async writer.
-		// The async writer is now responsible for calling putBuffer.
+// dispatch 将格式化好的日志交给异步写入器或直接写出, 并接管 buf 的回收.
+func (l *Logger) dispatch(level Level, buf *[]byte) error {
+	if l.asyncMode.Load() {
+		l.asyncMu.RLock()
+		// 持有读锁期间异步写入器不会被关闭或替换
+		if aw := l.asyncWriter; aw != nil && l.asyncMode.Load() {
+			handled, err := l.enqueue(aw, level, buf)
+			l.asyncMu.RUnlock()
+			if handled {
+				return err
+			}
+		} else {
+			l.asyncMu.RUnlock()
+		}
+	}
+	// Synchronous mode, or the async writer declined the entry. We own buf.
+	defer putBuffer(buf) // Ensure buffer is returned on this path
+	return l.write(level, *buf)
+}
+
+// enqueue 将日志投递给异步写入器. handled 为 false 时表示需要回退为同步写入, buf 仍归调用方所有.
+func (l *Logger) enqueue(aw *asyncWriter, level Level, buf *[]byte) (handled bool, err error) {
+	// Send the pointer to the buffer to the async writer.
+	// The async writer is now responsible for calling putBuffer.
+	select {
+	case aw.logChan <- logEntry{level: level, buf: buf}:
+		return true, nil
+	default:
+		l.asyncDrops.Add(1)
+	}
+	// Channel full, handle according to the overflow policy.
+	switch OverflowPolicy(l.overflow.Load()) {
+	case OverflowBlock:
+		ctx := context.Background()
+		if p := l.overflowCtx.Load(); p != nil {
+			ctx = *p
+		}
 		select {
-		case l.asyncWriter.logChan <- buf:
-			// Buffer ownership transferred to asyncWriter. It will call putBuffer.
-			// Do not call putBuffer(buf) here.
-			return nil
-		default:
-			// Channel full or async writer not ready, fallback to synchronous write.
-			// We (this goroutine) still own buf, so we must putBuffer it.
-			defer putBuffer(buf) // Ensure buffer is returned on this path
-			l.outMu.Lock()
-			_, err = l.out.Write(*buf)
-			l.outMu.Unlock()
+		case aw.logChan <- logEntry{level: level, buf: buf}:
+			return true, nil
+		case <-ctx.Done():
+			putBuffer(buf)
+			return true, ctx.Err()
+		case <-aw.stopping:
+			// 写入器正在关闭, 与关闭后的日志一样改为同步写入
+			return false, nil
 		}
-	} else {
-		// Synchronous mode or async not fully initialized. We own buf.
-		defer putBuffer(buf) // Ensure buffer is returned on this path
-		l.outMu.Lock()
-		_, err = l.out.Write(*buf)
-		l.outMu.Unlock()
+	case OverflowDropNewest:
+		putBuffer(buf)
+		return true, nil
 	}
-	return err
+	return false, nil
 }
 
 // Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
@@ -326,32 +786,32 @@ func itoa(buf *[]byte, i int, wid int) {
 func (l *Logger) Output(calldepth int, s string) error {
 	calldepth++ // +1 for this frame.
 	// Frame depth: 0: Output, 1: (Print|Printf|Println|Fatal|...), 2: caller of (Print|...)
-	return l.output(0, calldepth, func(b []byte) []byte {
+	return l.output(0, calldepth, LevelInfo, func(b []byte) []byte {
 		return append(b, s...)
 	})
 }
 
 func (l *Logger) Print(v ...any) {
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Append(b, v...)
 	})
 }
 
 func (l *Logger) Printf(format string, v ...any) {
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Appendf(b, format, v...)
 	})
 }
 
 func (l *Logger) Println(v ...any) {
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Appendln(b, v...)
 	})
 }
 
 func (l *Logger) Fatal(v ...any) {
 	s := fmt.Sprint(v...)
-	l.output(0, 2, func(b []byte) []byte { // Use output for consistent formatting and async handling
+	l.output(0, 2, LevelError, func(b []byte) []byte { // Use output for consistent formatting and async handling
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -359,7 +819,7 @@ func (l *Logger) Fatal(v ...any) {
 
 func (l *Logger) Fatalf(format string, v ...any) {
 	s := fmt.Sprintf(format, v...)
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -367,7 +827,7 @@ func (l *Logger) Fatalf(format string, v ...any) {
 
 func (l *Logger) Fatalln(v ...any) {
 	s := fmt.Sprintln(v...)
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -375,7 +835,7 @@ func (l *Logger) Fatalln(v ...any) {
 
 func (l *Logger) Panic(v ...any) {
 	s := fmt.Sprint(v...)
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
@@ -383,7 +843,7 @@ func (l *Logger) Panic(v ...any) {
 
 func (l *Logger) Panicf(format string, v ...any) {
 	s := fmt.Sprintf(format, v...)
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
@@ -391,12 +851,60 @@ func (l *Logger) Panicf(format string, v ...any) {
 
 func (l *Logger) Panicln(v ...any) {
 	s := fmt.Sprintln(v...)
-	l.output(0, 2, func(b []byte) []byte {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
 }
 
+func (l *Logger) Debug(v ...any) {
+	l.output(0, 2, LevelDebug, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func (l *Logger) Debugf(format string, v ...any) {
+	l.output(0, 2, LevelDebug, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func (l *Logger) Info(v ...any) {
+	l.output(0, 2, LevelInfo, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func (l *Logger) Infof(format string, v ...any) {
+	l.output(0, 2, LevelInfo, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func (l *Logger) Warn(v ...any) {
+	l.output(0, 2, LevelWarn, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func (l *Logger) Warnf(format string, v ...any) {
+	l.output(0, 2, LevelWarn, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func (l *Logger) Error(v ...any) {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func (l *Logger) Errorf(format string, v ...any) {
+	l.output(0, 2, LevelError, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
 func (l *Logger) Flags() int {
 	return int(l.flag.Load())
 }
@@ -405,6 +913,51 @@ func (l *Logger) SetFlags(flag int) {
 	l.flag.Store(int32(flag))
 }
 
+// SetCallerSkip 设置获取调用位置时额外跳过的栈帧数.
+// 在自己的辅助函数中包装 Logger 时, 每多一层包装就加 1, 使 Lshortfile 与 Llongfile 报告真正的调用方.
+func (l *Logger) SetCallerSkip(n int) {
+	l.callerSkip.Store(int32(n))
+}
+
+// SetSampler 设置采样间隔: 不高于采样等级 (默认 LevelDebug, 见 SetSampleLevel) 的日志每 n 条只输出第一条,
+// 其余只计数, 可通过 AsyncStats 查看. n <= 1 表示关闭采样.
+func (l *Logger) SetSampler(n int) {
+	l.sampleN.Store(int32(n))
+}
+
+// SetSampleLevel 设置参与采样的最高等级
+func (l *Logger) SetSampleLevel(level Level) {
+	l.sampleLevel.Store(int32(level))
+}
+
+// Level 返回当前的日志等级门限
+func (l *Logger) Level() Level {
+	return Level(l.level.Load())
+}
+
+// SetLevel 设置日志等级门限, 低于 level 的日志在格式化之前即被丢弃.
+// Print 系列按 LevelInfo 处理, Fatal 与 Panic 系列按 LevelError 处理.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+// Formatter 返回当前设置的 Formatter, 未设置时返回 nil
+func (l *Logger) Formatter() Formatter {
+	if f := l.formatter.Load(); f != nil {
+		return *f
+	}
+	return nil
+}
+
+// SetFormatter 设置自定义格式, 传入 nil 恢复默认的文本格式
+func (l *Logger) SetFormatter(f Formatter) {
+	if f == nil {
+		l.formatter.Store(nil)
+		return
+	}
+	l.formatter.Store(&f)
+}
+
 func (l *Logger) Prefix() string {
 	if p := l.prefix.Load(); p != nil {
 		return *p
@@ -417,6 +970,7 @@ func (l *Logger) SetPrefix(prefix string) {
 }
 
 func (l *Logger) Writer() io.Writer {
+	l = l.root()
 	l.outMu.Lock()
 	defer l.outMu.Unlock()
 	return l.out
@@ -426,6 +980,26 @@ func SetOutput(w io.Writer) {
 	std.SetOutput(w)
 }
 
+func AddOutput(w io.Writer) {
+	std.AddOutput(w)
+}
+
+func With(kv ...any) *Logger {
+	return std.With(kv...)
+}
+
+func SetColor(enable bool) {
+	std.SetColor(enable)
+}
+
+func AddHook(fn func(entry []byte)) {
+	std.AddHook(fn)
+}
+
+func SetHandler(h Handler) {
+	std.SetHandler(h)
+}
+
 func Flags() int {
 	return std.Flags()
 }
@@ -434,6 +1008,19 @@ func SetFlags(flag int) {
 	std.SetFlags(flag)
 }
 
+// GetLevel 返回标准 Logger 的日志等级门限
+func GetLevel() Level {
+	return std.Level()
+}
+
+func SetLevel(level Level) {
+	std.SetLevel(level)
+}
+
+func SetFormatter(f Formatter) {
+	std.SetFormatter(f)
+}
+
 func Prefix() string {
 	return std.Prefix()
 }
@@ -447,26 +1034,74 @@ func Writer() io.Writer {
 }
 
 func Print(v ...any) {
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Append(b, v...)
 	})
 }
 
 func Printf(format string, v ...any) {
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Appendf(b, format, v...)
 	})
 }
 
 func Println(v ...any) {
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelInfo, func(b []byte) []byte {
 		return fmt.Appendln(b, v...)
 	})
 }
 
+func Debug(v ...any) {
+	std.output(0, 2, LevelDebug, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func Debugf(format string, v ...any) {
+	std.output(0, 2, LevelDebug, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func Info(v ...any) {
+	std.output(0, 2, LevelInfo, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func Infof(format string, v ...any) {
+	std.output(0, 2, LevelInfo, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func Warn(v ...any) {
+	std.output(0, 2, LevelWarn, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func Warnf(format string, v ...any) {
+	std.output(0, 2, LevelWarn, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
+func Error(v ...any) {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
+		return fmt.Append(b, v...)
+	})
+}
+
+func Errorf(format string, v ...any) {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
+		return fmt.Appendf(b, format, v...)
+	})
+}
+
 func Fatal(v ...any) {
 	s := fmt.Sprint(v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -474,7 +1109,7 @@ func Fatal(v ...any) {
 
 func Fatalf(format string, v ...any) {
 	s := fmt.Sprintf(format, v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -482,7 +1117,7 @@ func Fatalf(format string, v ...any) {
 
 func Fatalln(v ...any) {
 	s := fmt.Sprintln(v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	os.Exit(1)
@@ -490,7 +1125,7 @@ func Fatalln(v ...any) {
 
 func Panic(v ...any) {
 	s := fmt.Sprint(v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
@@ -498,7 +1133,7 @@ func Panic(v ...any) {
 
 func Panicf(format string, v ...any) {
 	s := fmt.Sprintf(format, v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
@@ -506,7 +1141,7 @@ func Panicf(format string, v ...any) {
 
 func Panicln(v ...any) {
 	s := fmt.Sprintln(v...)
-	std.output(0, 2, func(b []byte) []byte {
+	std.output(0, 2, LevelError, func(b []byte) []byte {
 		return append(b, s...)
 	})
 	panic(s)
@@ -0,0 +1,790 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter 在 open 之前阻塞所有写入, 用于让异步通道积压日志. 可并发使用.
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	once    sync.Once
+	entered chan struct{} // 第一次写入开始时关闭
+	gate    chan struct{}
+	err     error // 非 nil 时, 放行后的写入都返回该错误
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{entered: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.entered) })
+	<-w.gate
+	if w.err != nil {
+		return 0, w.err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) open() { close(w.gate) }
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// numberedLines 返回 "prefix0\n" 到 "prefix(n-1)\n" 拼接的结果
+func numberedLines(prefix string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&sb, "%s%d\n", prefix, i)
+	}
+	return sb.String()
+}
+
+// logWrapper 模拟使用方对 Logger 的一层包装, 返回其中调用 Print 的行号
+func logWrapper(l *Logger, msg string) int {
+	_, _, line, _ := runtime.Caller(0)
+	l.Print(msg)
+	return line + 1
+}
+
+// TestSetCallerSkip 测试包装一层后, 设置 SetCallerSkip(1) 能报告真正的调用位置.
+func TestSetCallerSkip(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Lshortfile)
+
+	wrapperLine := logWrapper(l, "hello")
+	want := fmt.Sprintf("log_test.go:%d: hello\n", wrapperLine)
+	if buf.String() != want {
+		t.Fatalf("without skip: got %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	l.SetCallerSkip(1)
+	_, _, line, _ := runtime.Caller(0)
+	logWrapper(l, "hello")
+	want = fmt.Sprintf("log_test.go:%d: hello\n", line+1)
+	if buf.String() != want {
+		t.Fatalf("with skip: got %q, want %q", buf.String(), want)
+	}
+}
+
+// TestSlogHandler 测试 slog 记录的等级映射、属性与分组展开以及调用位置.
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Lshortfile|Llevel)
+	l.SetLevel(LevelInfo)
+	s := slog.New(l.SlogHandler()).With("app", "demo").WithGroup("req")
+
+	s.Debug("hidden")
+	_, _, line, _ := runtime.Caller(0)
+	s.Warn("hello world", "id", 7, slog.Group("user", "name", "a b"))
+	want := fmt.Sprintf("log_test.go:%d: [WARN] hello world app=demo req.id=7 req.user.name=\"a b\"\n", line+1)
+	if buf.String() != want {
+		t.Fatalf("got %q, want %q", buf.String(), want)
+	}
+}
+
+// TestFlush 测试 Flush 会等待调用前入队的日志按顺序全部写出, 且不会停止异步写入器.
+func TestFlush(t *testing.T) {
+	w := newGateWriter()
+	l := New(w, "", 0)
+	l.SetAsync(100)
+	defer l.Close()
+
+	for i := 0; i < 10; i++ {
+		l.Printf("line%d", i)
+	}
+	<-w.entered
+
+	flushed := make(chan error, 1)
+	go func() { flushed <- l.Flush() }()
+	select {
+	case <-flushed:
+		t.Fatal("Flush returned while writes were still blocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.open()
+	if err := <-flushed; err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := w.String(), numberedLines("line", 10); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// Flush 之后异步写入器仍在工作
+	l.Print("after")
+	if err := l.Flush(); err != nil {
+		t.Fatalf("second Flush: %v", err)
+	}
+	if !strings.HasSuffix(w.String(), "after\n") {
+		t.Fatalf("entry after Flush not written: %q", w.String())
+	}
+	if queued, _, _ := l.AsyncStats(); queued != 0 {
+		t.Fatalf("queued = %d after Flush, want 0", queued)
+	}
+
+	// 同步模式下 Flush 是空操作
+	if err := New(&bytes.Buffer{}, "", 0).Flush(); err != nil {
+		t.Fatalf("sync Flush: %v", err)
+	}
+}
+
+// delayWriter 每次写入前等待 delay
+type delayWriter struct {
+	delay time.Duration
+}
+
+func (w delayWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return len(p), nil
+}
+
+// TestWriteLatencyStats 测试同步与异步写入都计入耗时统计.
+func TestWriteLatencyStats(t *testing.T) {
+	l := New(delayWriter{delay: 20 * time.Millisecond}, "", 0)
+	if max, avg := l.WriteLatencyStats(); max != 0 || avg != 0 {
+		t.Fatalf("fresh logger: max=%v avg=%v, want 0", max, avg)
+	}
+
+	l.Print("sync")
+	max, avg := l.WriteLatencyStats()
+	if max < 20*time.Millisecond || avg < 20*time.Millisecond {
+		t.Fatalf("after sync write: max=%v avg=%v, want >= 20ms", max, avg)
+	}
+
+	l.SetOutput(&bytes.Buffer{})
+	l.SetAsync(10)
+	defer l.Close()
+	l.Print("async")
+	l.Flush()
+	max2, avg2 := l.WriteLatencyStats()
+	if max2 != max {
+		t.Fatalf("max changed from %v to %v after a fast write", max, max2)
+	}
+	if avg2 >= avg {
+		t.Fatalf("avg = %v after a fast async write, want < %v", avg2, avg)
+	}
+}
+
+// TestAsyncStats 测试 AsyncStats 报告异步通道中排队的日志数.
+func TestAsyncStats(t *testing.T) {
+	w := newGateWriter()
+	l := New(w, "", 0)
+	l.SetAsync(5)
+
+	l.Print("first")
+	<-w.entered // 第一条已被异步协程取出, 阻塞在写入中
+	for i := 0; i < 5; i++ {
+		l.Print(i)
+	}
+	if queued, dropped, suppressed := l.AsyncStats(); queued != 5 || dropped != 0 || suppressed != 0 {
+		t.Fatalf("AsyncStats() = %d, %d, %d, want 5, 0, 0", queued, dropped, suppressed)
+	}
+
+	w.open()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if queued, _, _ := l.AsyncStats(); queued != 0 {
+		t.Fatalf("queued = %d after Close, want 0", queued)
+	}
+}
+
+// TestAsyncOverflow 测试通道已满时三种策略的行为, 以及每次溢出都计入 dropped.
+func TestAsyncOverflow(t *testing.T) {
+	// fill 返回一个异步通道已满 (容量 1) 且异步协程阻塞在写入中的 Logger
+	fill := func(policy OverflowPolicy) (*Logger, *gateWriter) {
+		w := newGateWriter()
+		l := New(w, "", 0)
+		l.SetAsync(1)
+		l.SetAsyncOverflow(policy)
+		l.Print("first")
+		<-w.entered
+		l.Print("queued")
+		return l, w
+	}
+	dropped := func(l *Logger) int64 {
+		_, d, _ := l.AsyncStats()
+		return d
+	}
+
+	t.Run("FallbackSync", func(t *testing.T) {
+		l, w := fill(OverflowFallbackSync)
+		done := make(chan error, 1)
+		go func() { done <- l.Output(1, "overflow") }()
+		// 回退的同步写入与异步协程争用同一个输出, 在 open 之前无法完成
+		for dropped(l) != 1 {
+			time.Sleep(time.Millisecond)
+		}
+		w.open()
+		if err := <-done; err != nil {
+			t.Fatalf("Output: %v", err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		got := w.String()
+		for _, line := range []string{"first\n", "queued\n", "overflow\n"} {
+			if !strings.Contains(got, line) {
+				t.Fatalf("missing %q in %q", line, got)
+			}
+		}
+		if d := dropped(l); d != 1 {
+			t.Fatalf("dropped = %d, want 1", d)
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		l, w := fill(OverflowBlock)
+		done := make(chan error, 1)
+		go func() { done <- l.Output(1, "blocked") }()
+		select {
+		case err := <-done:
+			t.Fatalf("Output returned %v while the queue was full", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		w.open()
+		if err := <-done; err != nil {
+			t.Fatalf("Output: %v", err)
+		}
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if got, want := w.String(), "first\nqueued\nblocked\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		if d := dropped(l); d != 1 {
+			t.Fatalf("dropped = %d, want 1", d)
+		}
+	})
+
+	t.Run("BlockContext", func(t *testing.T) {
+		l, w := fill(OverflowBlock)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		l.SetAsyncOverflowContext(ctx)
+		if err := l.Output(1, "expired"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Output: got %v, want context.DeadlineExceeded", err)
+		}
+		w.open()
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if got, want := w.String(), "first\nqueued\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		if d := dropped(l); d != 1 {
+			t.Fatalf("dropped = %d, want 1", d)
+		}
+	})
+
+	t.Run("DropNewest", func(t *testing.T) {
+		l, w := fill(OverflowDropNewest)
+		for i := 0; i < 3; i++ {
+			if err := l.Output(1, "dropped"); err != nil {
+				t.Fatalf("Output: %v", err)
+			}
+		}
+		w.open()
+		if err := l.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		if got, want := w.String(), "first\nqueued\n"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+		if d := dropped(l); d != 3 {
+			t.Fatalf("dropped = %d, want 3", d)
+		}
+	})
+}
+
+// TestSetLevel 测试低于门限的日志被丢弃, 以及 Llevel 输出的等级标记.
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Llevel)
+	l.SetLevel(LevelWarn)
+	l.Debug("d")
+	l.Info("i")
+	l.Warn("w")
+	l.Errorf("e%d", 1)
+	if got, want := buf.String(), "[WARN] w\n[ERROR] e1\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if l.Level() != LevelWarn {
+		t.Fatalf("Level() = %v, want WARN", l.Level())
+	}
+
+	buf.Reset()
+	l.SetLevel(LevelDebug)
+	l.Debug("d")
+	l.Info("i")
+	if got, want := buf.String(), "[DEBUG] d\n[INFO] i\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// TestJSONFormatter 测试 JSONFormatter 输出的字段名、转义、调用位置以及 With 字段.
+func TestJSONFormatter(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "app: ", Lshortfile)
+	l.SetFormatter(JSONFormatter{})
+
+	_, _, line, _ := runtime.Caller(0)
+	l.With("n", 7, "ok", true, "err", errors.New("boom")).Print("say \"hi\"\n\tbye")
+	out := buf.String()
+	if !strings.HasSuffix(out, "}\n") || strings.Count(out, "\n") != 1 {
+		t.Fatalf("want a single JSON line, got %q", out)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if _, err := time.Parse(time.RFC3339Nano, fmt.Sprint(got["time"])); err != nil {
+		t.Fatalf("time: %v", err)
+	}
+	want := map[string]any{
+		"level":  "INFO",
+		"prefix": "app: ",
+		"file":   fmt.Sprintf("log_test.go:%d", line+1),
+		"msg":    "say \"hi\"\n\tbye",
+		"n":      float64(7),
+		"ok":     true,
+		"err":    "boom",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %#v, want %#v", k, got[k], v)
+		}
+	}
+	// 字段顺序固定: time, level, prefix, file, msg, 然后是键值对
+	order := []string{`"time"`, `"level"`, `"prefix"`, `"file"`, `"msg"`, `"n"`, `"ok"`, `"err"`}
+	for i := 1; i < len(order); i++ {
+		if strings.Index(out, order[i-1]) > strings.Index(out, order[i]) {
+			t.Errorf("%s should come before %s in %q", order[i-1], order[i], out)
+		}
+	}
+
+	// 无前缀且未设置 Lshortfile 时省略 prefix 与 file, 等级按实际调用的等级输出
+	buf.Reset()
+	l.SetPrefix("")
+	l.SetFlags(0)
+	l.Warn("plain")
+	got = nil
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+	}
+	if len(got) != 3 || got["level"] != "WARN" || got["msg"] != "plain" {
+		t.Fatalf("got %v, want only time, level and msg", got)
+	}
+}
+
+// errWriter 的每次写入都返回 err
+type errWriter struct{ err error }
+
+func (w errWriter) Write(p []byte) (int, error) { return 0, w.err }
+
+// TestAddOutput 测试日志写入所有输出, 失败的输出不影响其余输出且错误被合并, SetOutput 清除追加的输出.
+func TestAddOutput(t *testing.T) {
+	var a, b bytes.Buffer
+	errA, errB := errors.New("a"), errors.New("b")
+	l := New(&a, "", 0)
+	l.AddOutput(errWriter{errA})
+	l.AddOutput(&b)
+	l.AddOutput(errWriter{errB})
+
+	err := l.Output(1, "hello")
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Fatalf("Output: got %v, want both errors joined", err)
+	}
+	if a.String() != "hello\n" || b.String() != "hello\n" {
+		t.Fatalf("got %q and %q, want hello in both", a.String(), b.String())
+	}
+
+	// 主输出为 io.Discard 时, 追加的输出仍会收到日志
+	var c bytes.Buffer
+	l = New(io.Discard, "", 0)
+	l.AddOutput(&c)
+	l.Print("kept")
+	if c.String() != "kept\n" {
+		t.Fatalf("got %q, want %q", c.String(), "kept\n")
+	}
+
+	l.SetOutput(&a)
+	a.Reset()
+	l.Print("only")
+	if a.String() != "only\n" || c.String() != "kept\n" {
+		t.Fatalf("SetOutput did not clear added outputs: %q, %q", a.String(), c.String())
+	}
+}
+
+// TestWith 测试子 Logger 继承父 Logger 的字段与设置, 互不影响, 并共享输出.
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "p: ", 0)
+	parent := l.With("svc", "api")
+	child := parent.With("req", 42, "path", "/a b")
+
+	child.Print("hello")
+	parent.Print("parent")
+	l.Print("root")
+	want := "p: hello svc=api req=42 path=\"/a b\"\n" +
+		"p: parent svc=api\n" +
+		"p: root\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// 子 Logger 的前缀可独立修改, 输出目标的修改作用于根 Logger
+	buf.Reset()
+	child.SetPrefix("c: ")
+	var out bytes.Buffer
+	child.SetOutput(&out)
+	child.Print("x")
+	l.Print("y")
+	if buf.Len() != 0 {
+		t.Fatalf("old output still written: %q", buf.String())
+	}
+	if got, want := out.String(), "c: x svc=api req=42 path=\"/a b\"\np: y\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	// 落单的值使用 !BADKEY
+	out.Reset()
+	l.With("lonely").Print("m")
+	if got, want := out.String(), "p: m !BADKEY=lonely\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// TestClose 测试 Close 写出剩余日志, 返回排空时的第一个写入错误, 可重复调用, 之后回退为同步写入.
+func TestClose(t *testing.T) {
+	w := newGateWriter()
+	l := New(w, "", 0)
+	l.SetAsync(10)
+	for i := 0; i < 5; i++ {
+		l.Printf("line%d", i)
+	}
+	w.open()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if got, want := w.String(), numberedLines("line", 5); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	l.Print("sync")
+	if !strings.HasSuffix(w.String(), "sync\n") {
+		t.Fatalf("entry after Close not written synchronously: %q", w.String())
+	}
+
+	// 排空阶段的写入错误由 Close 返回, 重复调用返回相同的错误
+	errWrite := errors.New("write failed")
+	w = newGateWriter()
+	w.err = errWrite
+	l = New(w, "", 0)
+	l.SetAsync(10)
+	l.Print("blocking")
+	<-w.entered
+	l.Print("queued")
+	closed := make(chan error, 1)
+	go func() { closed <- l.Close() }()
+	for l.asyncMode.Load() {
+		time.Sleep(time.Millisecond)
+	}
+	w.open()
+	if err := <-closed; !errors.Is(err, errWrite) {
+		t.Fatalf("Close: got %v, want %v", err, errWrite)
+	}
+	if err := l.Close(); !errors.Is(err, errWrite) {
+		t.Fatalf("second Close: got %v, want %v", err, errWrite)
+	}
+
+	if err := New(io.Discard, "", 0).Close(); err != nil {
+		t.Fatalf("Close without async: %v", err)
+	}
+}
+
+// TestResizeAsyncBuffer 测试并发写入期间调整通道容量不会丢失日志, 也不会打乱同一协程的日志顺序.
+func TestResizeAsyncBuffer(t *testing.T) {
+	if err := New(io.Discard, "", 0).ResizeAsyncBuffer(8); !errors.Is(err, ErrAsyncDisabled) {
+		t.Fatalf("without async: got %v, want ErrAsyncDisabled", err)
+	}
+
+	w := newGateWriter()
+	w.open()
+	l := New(w, "", 0)
+	l.SetAsync(1)
+	l.SetAsyncOverflow(OverflowBlock)
+	if err := l.ResizeAsyncBuffer(-1); err == nil {
+		t.Fatal("negative size: want error")
+	}
+
+	const writers, perWriter = 4, 200
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				l.Printf("g%d-%d", g, i)
+			}
+		}()
+	}
+	stop := make(chan struct{})
+	resized := make(chan error, 1)
+	go func() {
+		var err error
+		for n := 0; err == nil; n++ {
+			select {
+			case <-stop:
+				resized <- nil
+				return
+			default:
+			}
+			err = l.ResizeAsyncBuffer(n%16 + 1)
+		}
+		resized <- err
+	}()
+	wg.Wait()
+	close(stop)
+	if err := <-resized; err != nil {
+		t.Fatalf("ResizeAsyncBuffer: %v", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	next := make([]int, writers)
+	for _, line := range strings.Split(strings.TrimSuffix(w.String(), "\n"), "\n") {
+		var g, i int
+		if _, err := fmt.Sscanf(line, "g%d-%d", &g, &i); err != nil {
+			t.Fatalf("unexpected line %q", line)
+		}
+		if i != next[g] {
+			t.Fatalf("writer %d: got entry %d, want %d", g, i, next[g])
+		}
+		next[g]++
+	}
+	for g, n := range next {
+		if n != perWriter {
+			t.Fatalf("writer %d: got %d entries, want %d", g, n, perWriter)
+		}
+	}
+}
+
+// TestSampler 测试采样只作用于不高于采样等级的日志, 每 n 条输出第一条, 其余计入 suppressed.
+func TestSampler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Llevel)
+	l.SetSampler(3)
+	for i := 0; i < 9; i++ {
+		l.Debugf("d%d", i)
+	}
+	l.Info("i")
+	if got, want := buf.String(), "[DEBUG] d0\n[DEBUG] d3\n[DEBUG] d6\n[INFO] i\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if _, _, suppressed := l.AsyncStats(); suppressed != 6 {
+		t.Fatalf("suppressed = %d, want 6", suppressed)
+	}
+
+	// 提高采样等级后 Info 也参与采样, 并发调用时输出条数仍按比例
+	buf.Reset()
+	l.SetSampleLevel(LevelInfo)
+	l.SetSampler(4)
+	l.sampleSeq.Store(0)
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				l.Info("x")
+			}
+		}()
+	}
+	wg.Wait()
+	l.Warn("w")
+	if got := strings.Count(buf.String(), "[INFO] x\n"); got != 100 {
+		t.Fatalf("sampled %d of 400 info entries, want 100", got)
+	}
+	if !strings.HasSuffix(buf.String(), "[WARN] w\n") {
+		t.Fatalf("warn entry was sampled: %q", buf.String())
+	}
+
+	buf.Reset()
+	l.SetSampler(1)
+	for i := 0; i < 5; i++ {
+		l.Debug("all")
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 5 {
+		t.Fatalf("SetSampler(1): got %d entries, want 5", got)
+	}
+}
+
+// TestCloseWithTimeout 测试输出卡住时 CloseWithTimeout 按时返回 context.DeadlineExceeded, 正常时等同于 Close.
+func TestCloseWithTimeout(t *testing.T) {
+	w := newGateWriter()
+	l := New(w, "", 0)
+	l.SetAsync(10)
+	l.Print("stuck")
+	l.Print("queued")
+	<-w.entered
+
+	start := time.Now()
+	if err := l.CloseWithTimeout(30 * time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("CloseWithTimeout took %v", elapsed)
+	}
+	// 写入恢复后异步协程仍会写完剩余日志并退出
+	w.open()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close after timeout: %v", err)
+	}
+	if got, want := w.String(), "stuck\nqueued\n"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+
+	w = newGateWriter()
+	w.open()
+	l = New(w, "", 0)
+	l.SetAsync(10)
+	for i := 0; i < 5; i++ {
+		l.Printf("line%d", i)
+	}
+	if err := l.CloseWithTimeout(5 * time.Second); err != nil {
+		t.Fatalf("CloseWithTimeout: %v", err)
+	}
+	if got, want := w.String(), numberedLines("line", 5); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+// TestCloseWithTimeoutBlocked 测试输出卡住且有调用方阻塞在投递或 Flush 上时, CloseWithTimeout 仍按时返回.
+func TestCloseWithTimeoutBlocked(t *testing.T) {
+	w := newGateWriter()
+	l := New(w, "", 0)
+	l.SetAsync(1)
+	l.SetAsyncOverflow(OverflowBlock)
+	l.Print("stuck")
+	<-w.entered
+	l.Print("queued")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		l.Print("blocked") // 通道已满, 阻塞在投递上
+	}()
+	go func() {
+		defer wg.Done()
+		l.Flush()
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- l.CloseWithTimeout(100 * time.Millisecond) }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("got %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("CloseWithTimeout did not return")
+	}
+
+	w.open()
+	wg.Wait()
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close after timeout: %v", err)
+	}
+	for _, line := range []string{"stuck\n", "queued\n", "blocked\n"} {
+		if !strings.Contains(w.String(), line) {
+			t.Fatalf("output %q missing %q", w.String(), line)
+		}
+	}
+}
+
+// TestAddHook 测试钩子按注册顺序收到每条日志, 钩子 panic 不影响写入, 异步模式下同样生效.
+func TestAddHook(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Llevel)
+	var mu sync.Mutex
+	var seen []string
+	l.AddHook(func(entry []byte) {
+		mu.Lock()
+		seen = append(seen, "first:"+string(entry))
+		mu.Unlock()
+	})
+	l.AddHook(func([]byte) { panic("boom") })
+	l.With("k", "v").AddHook(func(entry []byte) { // 子 Logger 的钩子注册到根 Logger
+		mu.Lock()
+		seen = append(seen, "last:"+string(entry))
+		mu.Unlock()
+	})
+
+	l.Warn("hello")
+	if got, want := buf.String(), "[WARN] hello\n"; got != want {
+		t.Fatalf("output: got %q, want %q", got, want)
+	}
+	if got, want := strings.Join(seen, ""), "first:[WARN] hello\nlast:[WARN] hello\n"; got != want {
+		t.Fatalf("hooks: got %q, want %q", got, want)
+	}
+
+	seen = nil
+	l.SetAsync(10)
+	for i := 0; i < 3; i++ {
+		l.Infof("a%d", i)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen) != 6 || seen[4] != "first:[INFO] a2\n" {
+		t.Fatalf("async hooks: got %q", seen)
+	}
+}
+
+// TestSlogLevels 测试 slog 等级到 Logger 等级的映射, 以及 Enabled 遵循 SetLevel.
+func TestSlogLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(&buf, "", Llevel)
+	l.SetLevel(LevelWarn)
+	h := l.SlogHandler()
+	ctx := context.Background()
+	if h.Enabled(ctx, slog.LevelInfo) || !h.Enabled(ctx, slog.LevelWarn) {
+		t.Fatal("Enabled does not follow SetLevel")
+	}
+
+	l.SetLevel(LevelDebug)
+	s := slog.New(h)
+	s.Log(ctx, slog.LevelDebug-4, "trace")
+	s.Log(ctx, slog.LevelInfo+2, "notice")
+	s.Log(ctx, slog.LevelError+4, "fatal")
+	s.With(slog.Group("g")).Info("empty", "", nil)
+	want := "[DEBUG] trace\n[INFO] notice\n[ERROR] fatal\n[INFO] empty\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
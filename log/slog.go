@@ -0,0 +1,91 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+)
+
+// slogHandler 将 log/slog 的记录交给 Logger 输出, 使 slog 可以复用 Logger 的格式、等级与异步写入器.
+type slogHandler struct {
+	l      *Logger
+	groups string // 当前分组前缀, 例如 "req.header."
+}
+
+// SlogHandler 返回一个以 l 为后端的 slog.Handler.
+// slog 的等级映射为 Logger 的等级 (低于 Info 为 Debug, 高于 Error 仍为 Error),
+// 属性以 key=value 的形式追加在消息之后, 分组以 "group.key" 的形式展开.
+// 调用位置取自记录的 PC, 时间使用 Logger 写入时的时间而不是记录中的时间.
+func (l *Logger) SlogHandler() slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// Enabled 实现 slog.Handler 接口.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return slogLevel(level) >= h.l.Level()
+}
+
+// Handle 实现 slog.Handler 接口.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	var extra []any
+	if r.NumAttrs() > 0 {
+		extra = make([]any, 0, 2*r.NumAttrs())
+		r.Attrs(func(a slog.Attr) bool {
+			extra = appendSlogAttr(extra, h.groups, a)
+			return true
+		})
+	}
+	return h.l.outputFields(r.PC, 3, slogLevel(r.Level), extra, func(b []byte) []byte {
+		return append(b, r.Message...)
+	})
+}
+
+// WithAttrs 实现 slog.Handler 接口, 属性通过 Logger.With 附加.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	var kv []any
+	for _, a := range attrs {
+		kv = appendSlogAttr(kv, h.groups, a)
+	}
+	return &slogHandler{l: h.l.With(kv...), groups: h.groups}
+}
+
+// WithGroup 实现 slog.Handler 接口.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &slogHandler{l: h.l, groups: h.groups + name + "."}
+}
+
+// slogLevel 将 slog 的等级映射为 Logger 的等级
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return LevelError
+	case level >= slog.LevelWarn:
+		return LevelWarn
+	case level >= slog.LevelInfo:
+		return LevelInfo
+	}
+	return LevelDebug
+}
+
+// appendSlogAttr 将属性展开为键值对追加到 kv, 分组属性递归展开, 空属性被忽略
+func appendSlogAttr(kv []any, groups string, a slog.Attr) []any {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return kv
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			groups += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			kv = appendSlogAttr(kv, groups, ga)
+		}
+		return kv
+	}
+	return append(kv, groups+a.Key, a.Value.Any())
+}
@@ -0,0 +1,15 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package log
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd 通过 TIOCGETA 判断 fd 是否为终端
+func isTerminalFd(fd uintptr) bool {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TIOCGETA, uintptr(unsafe.Pointer(&t)))
+	return errno == 0
+}
@@ -0,0 +1,13 @@
+package log
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// isTerminalFd 通过 TCGETS 判断 fd 是否为终端
+func isTerminalFd(fd uintptr) bool {
+	var t syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, syscall.TCGETS, uintptr(unsafe.Pointer(&t)))
+	return errno == 0
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd && !windows
+
+package log
+
+// isTerminalFd 在无法判断的平台上总是返回 false, 不输出颜色
+func isTerminalFd(fd uintptr) bool {
+	return false
+}
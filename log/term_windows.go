@@ -0,0 +1,9 @@
+package log
+
+import "syscall"
+
+// isTerminalFd 判断 fd 是否为控制台
+func isTerminalFd(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 WJQserver Studio. WJQserver Studio 2.0 License.
+*/
+
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format 日志的输出格式
+type Format int
+
+// 输出格式常量
+const (
+	FormatText Format = iota // 文本格式，例如 "2024-01-02T15:04:05Z - [INFO] msg"
+	FormatJSON               // JSON Lines 格式，例如 {"ts":"2024-01-02T15:04:05Z","level":"info","msg":"msg"}
+)
+
+// levelNames 是 logLevelMap 的反向映射，用于输出小写的等级名称
+var levelNames = func() map[int]string {
+	m := make(map[int]string, len(logLevelMap))
+	for name, lvl := range logLevelMap {
+		m[lvl] = name
+	}
+	return m
+}()
+
+// levelName 返回等级的小写名称，未知等级返回其数值
+func levelName(level int) string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return strconv.Itoa(level)
+}
+
+// levelTokens 文本格式中各等级的前缀，例如 "[INFO] "
+// warn 沿用早期版本的 "[WARNING] "，避免已有的日志过滤规则失效
+var levelTokens = func() map[int]string {
+	m := make(map[int]string, len(levelNames))
+	for lvl, name := range levelNames {
+		if lvl == LevelWarn {
+			name = "warning"
+		}
+		m[lvl] = "[" + strings.ToUpper(name) + "] "
+	}
+	return m
+}()
+
+// levelToken 返回文本格式的等级前缀，未知等级以数值表示，例如 "[7] "
+func levelToken(level int) string {
+	if token, ok := levelTokens[level]; ok {
+		return token
+	}
+	return "[" + strconv.Itoa(level) + "] "
+}
+
+// appendJSONLine 以 JSON 对象的形式追加一条日志，msg 末尾的换行不计入 msg 字段，caller 与 reqID 为空时省略
+func appendJSONLine(b []byte, ts string, level int, caller, reqID, msg string) []byte {
+	for len(msg) > 0 && msg[len(msg)-1] == '\n' {
+		msg = msg[:len(msg)-1]
+	}
+	b = append(b, `{"ts":`...)
+	b = appendJSONString(b, ts)
+	b = append(b, `,"level":`...)
+	b = appendJSONString(b, levelName(level))
+	if caller != "" {
+		b = append(b, `,"caller":`...)
+		b = appendJSONString(b, caller)
+	}
+	if reqID != "" {
+		b = append(b, `,"request_id":`...)
+		b = appendJSONString(b, reqID)
+	}
+	b = append(b, `,"msg":`...)
+	b = appendJSONString(b, msg)
+	return append(b, '}')
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 将 s 作为带引号的 JSON 字符串追加到 b，非法的 UTF-8 会被替换为 U+FFFD
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, "�"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -7,11 +7,16 @@ package logger
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,7 +26,8 @@ import (
 
 // 常量定义
 const (
-	timeFormat = time.RFC3339 // 日志时间格式
+	timeFormat               = time.RFC3339     // 日志时间格式
+	defaultSizeCheckInterval = 15 * time.Minute // 默认的日志大小检查间隔
 )
 
 // 日志等级常量
@@ -44,23 +50,127 @@ var logLevelMap = map[string]int{
 	"none":  LevelNone,
 }
 
+// RotatePolicy 日志按时间轮转的周期
+type RotatePolicy int
+
+// 轮转周期常量，按大小轮转始终生效
+const (
+	RotateSize   RotatePolicy = iota // 仅按大小轮转
+	RotateDaily                      // 每天零点额外轮转一次
+	RotateHourly                     // 每小时整点额外轮转一次
+)
+
+// periodStart 返回 t 所在周期的起点
+func (p RotatePolicy) periodStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch p {
+	case RotateDaily:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	case RotateHourly:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	}
+	return t
+}
+
+// nextPeriod 返回 start 之后下一个周期的起点
+func (p RotatePolicy) nextPeriod(start time.Time) time.Time {
+	if p == RotateHourly {
+		return start.Add(time.Hour)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// stampLayout 返回按周期轮转时备份文件名使用的时间格式
+func (p RotatePolicy) stampLayout() string {
+	if p == RotateHourly {
+		return "20060102-15"
+	}
+	return "20060102"
+}
+
 // Logger 结构体封装了日志记录器的功能
 type Logger struct {
-	logger       *log.Logger  // 日志记录器实例
-	logFile      *os.File     // 日志文件句柄
-	logLevel     atomic.Value // 当前日志等级
-	logFileMutex sync.Mutex   // 互斥锁，确保线程安全
-	maxLogSizeMB int64        // 最大日志文件大小（MB）
-	initOnce     sync.Once    // 确保初始化只执行一次
-	droppedLogs  int64        // 统计丢弃的日志数量（未使用）
+	logger       *log.Logger   // 日志记录器实例
+	logFile      *os.File      // 日志文件句柄
+	logFilePath  string        // 日志文件路径
+	logLevel     atomic.Value  // 当前日志等级
+	logFileMutex sync.Mutex    // 互斥锁，确保线程安全
+	maxLogSizeMB atomic.Int64  // 最大日志文件大小（MB）
+	rotation     RotatePolicy  // 按时间轮转的周期
+	maxBackups   int           // 保留的压缩备份数量上限，0 表示不限制
+	maxAge       time.Duration // 压缩备份的最长保留时间，0 表示不限制
+	sizeCheck    time.Duration // 定期检查日志大小的间隔
+	format       Format        // 输出格式
+	compression  Compression   // 备份的压缩方式
+	caller       bool          // 是否记录调用位置
+	rotateHook   atomic.Value  // 压缩备份生成后的回调，类型为 func(string)
+	written      atomic.Int64  // 自上次轮转以来写入的字节数
+	rotating     atomic.Bool   // 写入触发的轮转正在进行
+	stopMonitor  chan struct{} // 关闭时通知监控协程退出
+	droppedLogs  atomic.Int64  // 统计未初始化或关闭后被丢弃的日志数量
+
+	errLogger      *Logger // 额外接收高等级日志的实例，未配置时为 nil
+	errLogFilePath string  // errLogger 的日志文件路径
+	errLevel       int     // 写入 errLogger 的最低等级
+}
+
+// Config 描述一个 Logger 实例的配置，用于 New 与 InitWithConfig
+type Config struct {
+	LogFilePath  string // 日志文件路径，所在目录必须已存在
+	MaxLogSizeMB int    // 最大日志文件大小（MB），0 表示使用默认值 100
+	Level        string // 日志等级，如 "info"，为空时记录所有日志
+
+	// Rotation 按时间轮转的周期，与按大小轮转同时生效
+	// 按周期轮转的备份以周期起点命名，例如 app.log.20240102 或 app.log.20240102-15
+	Rotation RotatePolicy
+
+	MaxBackups int           // 保留的压缩备份数量上限，0 表示不限制
+	MaxAge     time.Duration // 压缩备份的最长保留时间，0 表示不限制
+
+	SizeCheckInterval time.Duration // 定期检查日志大小的间隔，不大于 0 时使用默认值 15 分钟
+	Format            Format        // 输出格式，默认为 FormatText
+	Compression       Compression   // 备份的压缩方式，为空时使用 CompressionGzip
+	Caller            bool          // 记录调用位置（文件名:行号），每条日志需额外调用 runtime.Caller
+
+	// ErrorLogFilePath 不为空时，等级不低于 ErrorLevel 的日志会额外写入该文件
+	// 该文件按相同的配置独立轮转
+	ErrorLogFilePath string
+	ErrorLevel       string // 写入 ErrorLogFilePath 的最低等级，为空时为 "error"
+}
+
+// Compression 轮转后备份文件的压缩方式，决定备份文件的扩展名
+type Compression string
+
+// 压缩方式常量
+const (
+	CompressionGzip Compression = "gzip" // tar+gzip 压缩，备份扩展名为 .tar.gz
+	CompressionNone Compression = "none" // 不压缩，保留重命名后的备份
+)
+
+// ext 返回压缩后备份文件追加的扩展名
+func (c Compression) ext() string {
+	if c == CompressionNone {
+		return ""
+	}
+	return ".tar.gz"
+}
+
+// New 按 cfg 创建并初始化一个独立的 Logger 实例
+// 不同实例拥有各自的日志文件、等级与轮转，可在同一进程中并存（例如访问日志与应用日志）
+func New(cfg Config) (*Logger, error) {
+	l := NewLogger()
+	if err := l.InitWithConfigStruct(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
 }
 
 // NewLogger 创建一个新的 Logger 实例
 func NewLogger() *Logger {
 	l := &Logger{
-		logLevel:     atomic.Value{}, // 初始化 atomic.Value
-		maxLogSizeMB: 100,            // 默认最大日志大小 100MB
+		logLevel: atomic.Value{}, // 初始化 atomic.Value
 	}
+	l.maxLogSizeMB.Store(100)   // 默认最大日志大小 100MB
 	l.logLevel.Store(LevelDump) // 默认日志级别为 LevelDump
 	return l
 }
@@ -75,30 +185,143 @@ func (l *Logger) SetLogLevelStruct(level string) error {
 	return fmt.Errorf("invalid log level: %s", level) // 返回错误信息
 }
 
+// GetLogLevel 返回当前日志等级的小写名称，如 "info"，未知等级返回其数值
+func (l *Logger) GetLogLevelStruct() string {
+	lvl, ok := l.logLevel.Load().(int)
+	if !ok {
+		lvl = LevelDump // 未设置时与 NewLogger 的默认等级一致
+	}
+	return levelName(lvl)
+}
+
 // Init 初始化日志记录器
+// 已初始化时直接返回 nil；Close 之后可以再次调用 Init 重新打开日志
 func (l *Logger) InitStruct(logFilePath string) error {
-	var initErr error
-	l.initOnce.Do(func() {
-		if err := l.validateLogFilePath(logFilePath); err != nil {
-			initErr = fmt.Errorf("invalid log file path: %w", err)
-			return
+	if err := l.validateLogFilePath(logFilePath); err != nil {
+		return fmt.Errorf("invalid log file path: %w", err)
+	}
+
+	l.logFileMutex.Lock()
+	defer l.logFileMutex.Unlock()
+	if l.logFile != nil {
+		return nil // 已初始化
+	}
+	return l.openLocked(logFilePath)
+}
+
+// openLocked 打开日志文件并启动大小监控，调用方需持有 logFileMutex 且日志文件尚未打开
+func (l *Logger) openLocked(logFilePath string) error {
+	logFile, err := os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	if l.errLogger != nil {
+		if err := l.errLogger.InitStruct(l.errLogFilePath); err != nil {
+			logFile.Close()
+			return fmt.Errorf("failed to open error log file: %w", err)
 		}
+	}
+	l.startLocked(logFile, logFilePath)
+	return nil
+}
 
-		l.logFileMutex.Lock()
-		defer l.logFileMutex.Unlock()
+// startLocked 使用已打开的日志文件并启动大小监控，调用方需持有 logFileMutex
+func (l *Logger) startLocked(logFile *os.File, logFilePath string) {
+	l.logFile = logFile
+	l.logFilePath = logFilePath
+	l.written.Store(0)
+	if info, err := l.logFile.Stat(); err == nil {
+		l.written.Store(info.Size()) // 追加写入时从已有大小开始计数
+	}
+	// 移除标准日志标志，以便手动控制时间格式
+	l.logger = log.New(l.logFile, "", 0)
+	l.stopMonitor = make(chan struct{})
+	go l.monitorLogSize(logFilePath, l.maxLogSizeMB.Load()*1024*1024, l.sizeCheck, l.rotation, l.stopMonitor) // 启动日志文件大小监控
+}
 
-		var err error
-		l.logFile, err = os.OpenFile(logFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			initErr = fmt.Errorf("failed to open log file: %w", err)
-			return
+// InitWithConfig 按配置初始化日志记录器
+// 已初始化或配置无效时返回错误且不修改任何配置；Close 之后可以用新的配置再次初始化
+func (l *Logger) InitWithConfigStruct(cfg Config) error {
+	if err := l.validateLogFilePath(cfg.LogFilePath); err != nil {
+		return fmt.Errorf("invalid log file path: %w", err)
+	}
+	if err := checkConfig(cfg); err != nil {
+		return err
+	}
+
+	// 持有锁完成检查与配置，避免与写入和监控协程并发修改字段
+	l.logFileMutex.Lock()
+	defer l.logFileMutex.Unlock()
+	if l.logFile != nil {
+		return fmt.Errorf("logger is already initialized") // 需先 Close 再以新配置初始化
+	}
+
+	logFile, err := os.OpenFile(cfg.LogFilePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	var errLogger *Logger
+	if cfg.ErrorLogFilePath != "" {
+		// 高等级日志文件使用独立的实例，拥有各自的轮转与备份
+		errCfg := cfg
+		errCfg.Level = ""
+		errCfg.ErrorLogFilePath = ""
+		errCfg.ErrorLevel = ""
+		errLogger = NewLogger()
+		errLogger.configure(errCfg)
+		if err := errLogger.InitStruct(cfg.ErrorLogFilePath); err != nil {
+			logFile.Close()
+			return fmt.Errorf("failed to open error log file: %w", err)
 		}
+	}
 
-		// 移除标准日志标志，以便手动控制时间格式
-		l.logger = log.New(l.logFile, "", 0)
-		go l.monitorLogSize(logFilePath, l.maxLogSizeMB*1024*1024) // 启动日志文件大小监控
-	})
-	return initErr
+	// 文件均已打开，此后不会再失败，才开始应用配置
+	l.configure(cfg)
+	// 替换前关闭旧的 errLogger，避免泄漏其文件句柄与监控协程
+	if l.errLogger != nil {
+		l.errLogger.CloseStruct()
+	}
+	l.errLogger = errLogger
+	l.errLogFilePath = cfg.ErrorLogFilePath
+	l.startLocked(logFile, cfg.LogFilePath)
+	return nil
+}
+
+// checkConfig 检查 cfg 中需要解析的字段，在修改任何配置之前调用
+func checkConfig(cfg Config) error {
+	for _, level := range []string{cfg.Level, cfg.ErrorLevel} {
+		if _, ok := logLevelMap[strings.ToLower(level)]; level != "" && !ok {
+			return fmt.Errorf("invalid log level: %s", level)
+		}
+	}
+	switch cfg.Compression {
+	case "", CompressionGzip, CompressionNone:
+	default:
+		return fmt.Errorf("unsupported compression: %s", cfg.Compression)
+	}
+	return nil
+}
+
+// configure 应用除日志文件路径以外的配置，cfg 需已通过 checkConfig
+func (l *Logger) configure(cfg Config) {
+	if cfg.MaxLogSizeMB > 0 {
+		l.SetMaxLogSizeMBStruct(cfg.MaxLogSizeMB)
+	}
+	if cfg.Level != "" {
+		l.SetLogLevelStruct(cfg.Level)
+	}
+	l.rotation = cfg.Rotation
+	l.maxBackups = cfg.MaxBackups
+	l.maxAge = cfg.MaxAge
+	l.sizeCheck = cfg.SizeCheckInterval
+	l.format = cfg.Format
+	l.caller = cfg.Caller
+	l.compression = cfg.Compression
+	l.errLevel = LevelError
+	if cfg.ErrorLevel != "" {
+		l.errLevel = logLevelMap[strings.ToLower(cfg.ErrorLevel)]
+	}
 }
 
 // validateLogFilePath 验证日志文件路径的有效性
@@ -112,69 +335,175 @@ func (l *Logger) validateLogFilePath(path string) error {
 
 // SetMaxLogSizeMB 设置最大日志文件大小（MB）
 func (l *Logger) SetMaxLogSizeMBStruct(maxSizeMB int) {
-	l.maxLogSizeMB = int64(maxSizeMB) // 更新最大日志大小
+	l.maxLogSizeMB.Store(int64(maxSizeMB)) // 更新最大日志大小
 }
 
 // Log 记录日志
 func (l *Logger) LogStruct(level int, msg string) {
+	l.output(2, level, "", msg)
+}
+
+// RequestIDKey 是请求 ID 在 context 中的键，通常通过 WithRequestID 设置
+type RequestIDKey struct{}
+
+// WithRequestID 返回携带请求 ID 的 context，供 LogCtx 输出
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey{}, id)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID，不存在时返回空字符串
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey{}).(string)
+	return id
+}
+
+// LogCtx 记录日志，ctx 中携带请求 ID 时输出在消息之前
+// 文本格式为 "[INFO] [请求ID] msg"，JSON 格式为 request_id 字段
+func (l *Logger) LogCtxStruct(ctx context.Context, level int, msg string) {
+	l.output(2, level, RequestIDFromContext(ctx), msg)
+}
+
+// output 记录一条日志，calldepth 为获取调用位置时跳过的栈帧数，1 表示 output 的调用方
+// reqID 不为空时作为请求 ID 输出
+func (l *Logger) output(calldepth int, level int, reqID string, msg string) {
 	if level < l.logLevel.Load().(int) {
 		return // 如果当前日志等级低于设定等级，则不记录
 	}
 
-	logPrefix := ""
-	switch level {
-	case LevelDump:
-		logPrefix = "[DUMP] "
-	case LevelDebug:
-		logPrefix = "[DEBUG] "
-	case LevelInfo:
-		logPrefix = "[INFO] "
-	case LevelWarn:
-		logPrefix = "[WARNING] "
-	case LevelError:
-		logPrefix = "[ERROR] "
+	logPrefix := levelToken(level) // 由等级本身生成，直接调用 Log/Logf 时同样可靠
+
+	// 配置可能被 InitWithConfig 并发修改，持锁读取一份快照
+	l.logFileMutex.Lock()
+	format, withCaller := l.format, l.caller
+	errLogger, errLevel := l.errLogger, l.errLevel
+	l.logFileMutex.Unlock()
+
+	// 获取调用位置开销较大，仅在开启时进行
+	var caller string
+	if withCaller {
+		caller = "???:0"
+		if _, file, line, ok := runtime.Caller(calldepth); ok {
+			caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+		}
+	}
+
+	// 手动格式化时间
+	ts := time.Now().Format(timeFormat)
+	var line string
+	if format == FormatJSON {
+		line = string(appendJSONLine(make([]byte, 0, len(msg)+64), ts, level, caller, reqID, msg))
+	} else {
+		if reqID != "" {
+			msg = "[" + reqID + "] " + msg
+		}
+		if caller != "" {
+			msg = caller + ": " + msg
+		}
+		line = ts + " - " + logPrefix + msg
 	}
+	l.writeLine(line)
+	if errLogger != nil && level >= errLevel {
+		errLogger.writeLine(line)
+	}
+}
 
+// writeLine 将格式化好的一行写入日志文件，并在写入量超过上限时轮转
+func (l *Logger) writeLine(line string) {
 	l.logFileMutex.Lock()
-	defer l.logFileMutex.Unlock()
-	// 手动格式化时间并记录日志
-	l.logger.Printf("%s - %s%s", time.Now().Format(timeFormat), logPrefix, msg)
+	if l.logFile == nil {
+		l.logFileMutex.Unlock()
+		l.droppedLogs.Add(1)
+		return // 未初始化或已关闭，丢弃日志
+	}
+	l.logger.Print(line)
+	l.logFileMutex.Unlock()
+
+	// 按写入字节数即时触发轮转，定期检查仅作为兜底
+	n := len(line)
+	if !strings.HasSuffix(line, "\n") {
+		n++ // log.Logger 会补齐换行
+	}
+	maxBytes := l.maxLogSizeMB.Load() * 1024 * 1024
+	if maxBytes > 0 && l.written.Add(int64(n)) > maxBytes && l.rotating.CompareAndSwap(false, true) {
+		defer l.rotating.Store(false)
+		if err := l.RotateStruct(); err != nil {
+			l.written.Store(0)                                       // 失败后重新计数，避免每条日志都重试
+			fmt.Fprintf(os.Stderr, "Log rotation failed: %v\n", err) // 此处不能再写日志，避免递归
+		}
+	}
 }
 
 // Logf 格式化日志记录
 func (l *Logger) LogfStruct(level int, format string, args ...interface{}) {
-	l.LogStruct(level, fmt.Sprintf(format, args...)) // 调用 LogStruct 记录格式化日志
+	l.outputf(2, level, format, args...)
+}
+
+// outputf 格式化后调用 output 记录日志
+func (l *Logger) outputf(calldepth int, level int, format string, args ...interface{}) {
+	l.output(calldepth+1, level, "", fmt.Sprintf(format, args...))
 }
 
 // LogDump 快捷日志方法
 func (l *Logger) LogDumpStruct(format string, args ...interface{}) {
-	l.LogfStruct(LevelDump, format, args...) // 记录 DUMP 级别日志
+	l.outputf(2, LevelDump, format, args...) // 记录 DUMP 级别日志
 }
 
 // LogDebug 快捷日志方法
 func (l *Logger) LogDebugStruct(format string, args ...interface{}) {
-	l.LogfStruct(LevelDebug, format, args...) // 记录 DEBUG 级别日志
+	l.outputf(2, LevelDebug, format, args...) // 记录 DEBUG 级别日志
 }
 
 // LogInfo 快捷日志方法
 func (l *Logger) LogInfoStruct(format string, args ...interface{}) {
-	l.LogfStruct(LevelInfo, format, args...) // 记录 INFO 级别日志
+	l.outputf(2, LevelInfo, format, args...) // 记录 INFO 级别日志
 }
 
 // LogWarning 快捷日志方法
 func (l *Logger) LogWarningStruct(format string, args ...interface{}) {
-	l.LogfStruct(LevelWarn, format, args...) // 记录 WARNING 级别日志
+	l.outputf(2, LevelWarn, format, args...) // 记录 WARNING 级别日志
 }
 
 // LogError 快捷日志方法
 func (l *Logger) LogErrorStruct(format string, args ...interface{}) {
-	l.LogfStruct(LevelError, format, args...) // 记录 ERROR 级别日志
+	l.outputf(2, LevelError, format, args...) // 记录 ERROR 级别日志
 }
 
-// Close 关闭日志系统
+// DumpDroppedLogs 返回累计丢弃的日志数量（未初始化或关闭后记录的日志），不会清零
+func (l *Logger) DumpDroppedLogsStruct() int64 {
+	return l.droppedLogs.Load()
+}
+
+// ResetDroppedLogs 将丢弃计数清零并返回清零前的值，便于按采集周期计算丢弃速率
+func (l *Logger) ResetDroppedLogsStruct() int64 {
+	return l.droppedLogs.Swap(0)
+}
+
+// Flush 将已写入的日志同步到磁盘，未初始化或已关闭时不做任何事
+// 日志在每次记录时已直接写入文件，Flush 额外调用 fsync 以确保内容落盘
+func (l *Logger) FlushStruct() error {
+	var errs []error
+	if l.errLogger != nil {
+		errs = append(errs, l.errLogger.FlushStruct())
+	}
+	l.logFileMutex.Lock()
+	defer l.logFileMutex.Unlock()
+	if l.logFile != nil {
+		errs = append(errs, l.logFile.Sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Close 关闭日志系统，之后可以再次调用 Init 重新初始化
 func (l *Logger) CloseStruct() {
+	if l.errLogger != nil {
+		l.errLogger.CloseStruct()
+	}
 	l.logFileMutex.Lock()
 	defer l.logFileMutex.Unlock()
+	if l.stopMonitor != nil {
+		close(l.stopMonitor) // 停止监控协程，重新初始化时会启动新的监控
+		l.stopMonitor = nil
+	}
 	if l.logFile != nil {
 		if err := l.logFile.Close(); err != nil {
 			fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", err) // 输出关闭日志文件时的错误
@@ -183,11 +512,38 @@ func (l *Logger) CloseStruct() {
 	}
 }
 
+// SetRotateHook 设置轮转回调，每次轮转生成压缩备份后以其路径调用 fn，传入 nil 取消回调
+// fn 在后台的压缩协程中执行，相对于触发轮转的写入是异步的；执行时不持有日志文件锁，可以进行耗时操作（如上传备份）
+func (l *Logger) SetRotateHookStruct(fn func(backupPath string)) {
+	l.rotateHook.Store(fn)
+}
+
+// RotateStruct 立即轮转日志文件，与按大小触发的轮转步骤相同
+func (l *Logger) RotateStruct() error {
+	l.logFileMutex.Lock()
+	logFilePath := l.logFilePath
+	opened := l.logFile != nil
+	l.logFileMutex.Unlock()
+	if !opened {
+		return fmt.Errorf("logger is not initialized or already closed") // 未初始化或已关闭
+	}
+	return l.rotateLogFile(logFilePath)
+}
+
 // monitorLogSize 定期检查日志文件大小
-func (l *Logger) monitorLogSize(logFilePath string, maxBytes int64) {
+// interval 不大于 0 时使用默认间隔；配置在启动时传入，重新初始化时不会与仍在退出的旧协程竞争
+func (l *Logger) monitorLogSize(logFilePath string, maxBytes int64, interval time.Duration, rotation RotatePolicy, stop <-chan struct{}) {
+	if interval <= 0 {
+		interval = defaultSizeCheckInterval
+	}
+
 	// 预检测一次
 	go func() {
-		time.Sleep(30 * time.Second)
+		select {
+		case <-time.After(30 * time.Second):
+		case <-stop:
+			return
+		}
 		l.logFileMutex.Lock()
 		info, err := l.logFile.Stat() // 获取日志文件信息
 		l.logFileMutex.Unlock()
@@ -199,34 +555,74 @@ func (l *Logger) monitorLogSize(logFilePath string, maxBytes int64) {
 		}
 	}()
 
-	ticker := time.NewTicker(15 * time.Minute) // 每 15 分钟检查一次
+	ticker := time.NewTicker(interval) // 每隔 interval 检查一次
 	defer ticker.Stop()
 
-	for range ticker.C {
-		l.logFileMutex.Lock()
-		info, err := l.logFile.Stat() // 获取日志文件信息
-		l.logFileMutex.Unlock()
+	// 按时间轮转时在每个周期的起点唤醒一次
+	var periodTimer *time.Timer
+	var periodC <-chan time.Time
+	period := rotation.periodStart(time.Now())
+	if rotation != RotateSize {
+		periodTimer = time.NewTimer(time.Until(rotation.nextPeriod(period)))
+		defer periodTimer.Stop()
+		periodC = periodTimer.C
+	}
 
-		if err == nil && info.Size() > maxBytes {
-			if err := l.rotateLogFile(logFilePath); err != nil {
-				l.LogErrorStruct("Log rotation failed: %v", err) // 记录日志轮转失败的错误
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			l.logFileMutex.Lock()
+			info, err := l.logFile.Stat() // 获取日志文件信息
+			l.logFileMutex.Unlock()
+
+			if err == nil && info.Size() > maxBytes {
+				if err := l.rotateLogFile(logFilePath); err != nil {
+					l.LogErrorStruct("Log rotation failed: %v", err) // 记录日志轮转失败的错误
+				}
+			}
+		case now := <-periodC:
+			// 周期已经结束，备份以刚结束的周期起点命名
+			if cur := rotation.periodStart(now); cur.After(period) {
+				if err := l.rotateLogFileAs(logFilePath, period.Format(rotation.stampLayout())); err != nil {
+					l.LogErrorStruct("Log rotation failed: %v", err) // 记录日志轮转失败的错误
+				}
+				period = cur
 			}
+			periodTimer.Reset(time.Until(rotation.nextPeriod(period)))
 		}
 	}
 }
 
-// rotateLogFile 轮转日志文件
+// backupLayout 按大小或手动轮转时备份文件名使用的时间格式
+const backupLayout = "20060102-150405"
+
+// backupLayouts 备份文件名中可能出现的全部时间格式，用于识别备份
+var backupLayouts = []string{backupLayout, RotateHourly.stampLayout(), RotateDaily.stampLayout()}
+
+// rotateLogFile 轮转日志文件，备份以当前时间命名
 func (l *Logger) rotateLogFile(logFilePath string) error {
+	return l.rotateLogFileAs(logFilePath, time.Now().Format(backupLayout))
+}
+
+// rotateLogFileAs 轮转日志文件，备份命名为 "日志文件路径.stamp"
+func (l *Logger) rotateLogFileAs(logFilePath, stamp string) error {
 	l.logFileMutex.Lock()
 	defer l.logFileMutex.Unlock()
 
-	if l.logFile != nil {
-		if err := l.logFile.Close(); err != nil {
-			return fmt.Errorf("error closing log file: %w", err) // 返回关闭日志文件时的错误
-		}
+	if l.logFile == nil {
+		return fmt.Errorf("logger is not initialized or already closed") // 已关闭时不能重新打开日志文件
+	}
+	if err := l.logFile.Close(); err != nil {
+		return fmt.Errorf("error closing log file: %w", err) // 返回关闭日志文件时的错误
 	}
 
-	backupPath := fmt.Sprintf("%s.%s", logFilePath, time.Now().Format("20060102-150405")) // 生成备份文件名
+	backupPath := fmt.Sprintf("%s.%s", logFilePath, stamp) // 生成备份文件名
+	// 同一秒内多次轮转时避免覆盖尚未压缩的备份
+	for i := 1; fileExists(backupPath) || fileExists(backupPath+".tar.gz"); i++ {
+		backupPath = fmt.Sprintf("%s.%s.%d", logFilePath, stamp, i)
+	}
 	if err := os.Rename(logFilePath, backupPath); err != nil {
 		return fmt.Errorf("error renaming log file: %w", err) // 返回重命名日志文件时的错误
 	}
@@ -236,19 +632,123 @@ func (l *Logger) rotateLogFile(logFilePath string) error {
 		return fmt.Errorf("error creating new log file: %w", err) // 返回创建新日志文件时的错误
 	}
 	l.logFile = newFile
+	l.written.Store(0)
 	l.logger.SetOutput(l.logFile) // 更新 logger 的输出目标
 
-	go func() {
+	go l.archiveBackup(logFilePath, backupPath, l.retentionLocked())
+
+	return nil
+}
+
+// retention 备份的压缩与保留配置，轮转时在锁内取得快照，后台压缩与清理不再读取可被重新初始化修改的字段
+type retention struct {
+	compression Compression
+	maxBackups  int
+	maxAge      time.Duration
+	skip        string // 同目录下错误日志文件的路径，清理时跳过，由错误日志实例自己管理
+}
+
+// retentionLocked 返回当前的备份配置，调用方需持有 logFileMutex
+func (l *Logger) retentionLocked() retention {
+	return retention{l.compression, l.maxBackups, l.maxAge, l.errLogFilePath}
+}
+
+// archiveBackup 压缩备份文件并清理旧备份
+// 只有压缩成功后才删除原始备份，压缩失败时保留原始备份以免丢失日志
+func (l *Logger) archiveBackup(logFilePath, backupPath string, r retention) {
+	archivePath := backupPath + r.compression.ext()
+	if r.compression != CompressionNone {
 		if err := l.compressLog(backupPath); err != nil {
-			l.LogErrorStruct("Compression failed: %v", err) // 记录压缩失败的错误
+			l.LogErrorStruct("Compression failed, keeping raw backup %s: %v", backupPath, err) // 记录压缩失败的错误
+			// 删除不完整的压缩文件
+			os.Remove(archivePath)
+			return
 		}
 		if err := os.Remove(backupPath); err != nil {
 			l.LogErrorStruct("Failed to remove backup file: %v", err) // 记录删除备份文件失败的错误
 			fmt.Printf("Failed to remove backup file: %v\n", err)
 		}
-	}()
+	}
+	if hook, _ := l.rotateHook.Load().(func(string)); hook != nil {
+		hook(archivePath) // 不持有 logFileMutex，不会阻塞日志写入
+	}
+	if err := cleanupBackups(logFilePath, r); err != nil {
+		l.LogErrorStruct("Backup cleanup failed: %v", err) // 记录清理旧备份失败的错误
+	}
+}
 
-	return nil
+// cleanupBackups 按 r 的 maxBackups 与 maxAge 删除 logFilePath 旧的备份，按修改时间从新到旧保留
+// 主日志与错误日志各自只清理自己的备份，互不计入对方的数量上限
+func cleanupBackups(logFilePath string, r retention) error {
+	if r.maxBackups <= 0 && r.maxAge <= 0 {
+		return nil
+	}
+	ext := r.compression.ext()
+	matches, err := filepath.Glob(logFilePath + ".*" + ext)
+	if err != nil {
+		return err
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	backups := make([]backup, 0, len(matches))
+	for _, path := range matches {
+		if path == r.skip || !isBackupOf(path, logFilePath, ext) {
+			continue // 通配符同样会匹配到其他文件，例如 app.log.error
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue // 可能已被并发的清理删除
+		}
+		backups = append(backups, backup{path, info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].modTime.After(backups[j].modTime)
+	})
+
+	var errs []error
+	cutoff := time.Now().Add(-r.maxAge)
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.maxAge > 0 && b.modTime.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isBackupOf 判断 path 是否为轮转 logFilePath 生成的备份
+// 备份命名为 "日志文件路径.时间[.序号]" 加上压缩扩展名 ext，时间须符合 backupLayouts 之一
+func isBackupOf(path, logFilePath, ext string) bool {
+	stamp, ok := strings.CutPrefix(path, logFilePath+".")
+	if !ok {
+		return false
+	}
+	if stamp, ok = strings.CutSuffix(stamp, ext); !ok {
+		return false
+	}
+	// 同一时间多次轮转时追加的序号
+	if i := strings.LastIndexByte(stamp, '.'); i >= 0 {
+		if _, err := strconv.ParseUint(stamp[i+1:], 10, 0); err != nil {
+			return false
+		}
+		stamp = stamp[:i]
+	}
+	for _, layout := range backupLayouts {
+		if _, err := time.Parse(layout, stamp); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// fileExists 判断路径是否存在
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // compressLog 压缩日志文件
@@ -291,7 +791,14 @@ func (l *Logger) compressLog(srcPath string) error {
 		return err // 复制文件内容时的错误
 	}
 
-	return nil
+	// 显式关闭以确认压缩数据已完整写入，调用方据此决定是否删除原始备份
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	if err := gzWriter.Close(); err != nil {
+		return err
+	}
+	return dstFile.Close()
 }
 
 // 全局 Logger 实例
@@ -311,48 +818,88 @@ func Init(logFilePath string, maxLogSizeMB int) error {
 	return defaultLogger.InitStruct(logFilePath)      // 调用内部的 InitStruct
 }
 
+// 按配置初始化
+func InitWithConfig(cfg Config) error {
+	return defaultLogger.InitWithConfigStruct(cfg) // 调用内部的 InitWithConfigStruct
+}
+
 // 设置日志等级
 func SetLogLevel(level string) error {
 	return defaultLogger.SetLogLevelStruct(level) // 调用内部的 SetLogLevelStruct
 }
 
+// 获取当前日志等级
+func GetLogLevel() string {
+	return defaultLogger.GetLogLevelStruct() // 调用内部的 GetLogLevelStruct
+}
+
 // 设置最大日志文件大小（MB）
 func SetMaxLogSizeMB(maxSizeMB int) {
 	defaultLogger.SetMaxLogSizeMBStruct(maxSizeMB) // 调用内部的 SetMaxLogSizeMBStruct
 }
 
+// 将日志同步到磁盘
+func Flush() error {
+	return defaultLogger.FlushStruct() // 调用内部的 FlushStruct
+}
+
+// 获取累计丢弃的日志数量
+func DumpDroppedLogs() int64 {
+	return defaultLogger.DumpDroppedLogsStruct() // 调用内部的 DumpDroppedLogsStruct
+}
+
+// 获取并清零丢弃的日志数量
+func ResetDroppedLogs() int64 {
+	return defaultLogger.ResetDroppedLogsStruct() // 调用内部的 ResetDroppedLogsStruct
+}
+
 // 关闭日志系统
 func Close() {
 	defaultLogger.CloseStruct() // 调用内部的 CloseStruct
 }
 
+// 设置轮转回调
+func SetRotateHook(fn func(backupPath string)) {
+	defaultLogger.SetRotateHookStruct(fn) // 调用内部的 SetRotateHookStruct
+}
+
+// 立即轮转日志文件
+func Rotate() error {
+	return defaultLogger.RotateStruct() // 调用内部的 RotateStruct
+}
+
 // 日志记录函数，使用原有的函数名称
 func Log(level int, msg string) {
-	defaultLogger.LogStruct(level, msg) // 调用内部的 LogStruct
+	defaultLogger.output(2, level, "", msg) // 直接调用内部的 output，保证调用位置正确
+}
+
+// 携带 context 的日志记录函数
+func LogCtx(ctx context.Context, level int, msg string) {
+	defaultLogger.output(2, level, RequestIDFromContext(ctx), msg)
 }
 
 // 格式化日志记录函数，使用原有的函数名称
 func Logf(level int, format string, args ...interface{}) {
-	defaultLogger.LogfStruct(level, format, args...) // 调用内部的 LogfStruct
+	defaultLogger.outputf(2, level, format, args...)
 }
 
 // 不同日志等级的快捷函数，使用原有的函数名称
 func LogDump(format string, args ...interface{}) {
-	defaultLogger.LogDumpStruct(format, args...) // 调用内部的 LogDumpStruct
+	defaultLogger.outputf(2, LevelDump, format, args...)
 }
 
 func LogDebug(format string, args ...interface{}) {
-	defaultLogger.LogDebugStruct(format, args...) // 调用内部的 LogDebugStruct
+	defaultLogger.outputf(2, LevelDebug, format, args...)
 }
 
 func LogInfo(format string, args ...interface{}) {
-	defaultLogger.LogInfoStruct(format, args...) // 调用内部的 LogInfoStruct
+	defaultLogger.outputf(2, LevelInfo, format, args...)
 }
 
 func LogWarning(format string, args ...interface{}) {
-	defaultLogger.LogWarningStruct(format, args...) // 调用内部的 LogWarningStruct
+	defaultLogger.outputf(2, LevelWarn, format, args...)
 }
 
 func LogError(format string, args ...interface{}) {
-	defaultLogger.LogErrorStruct(format, args...) // 调用内部的 LogErrorStruct
+	defaultLogger.outputf(2, LevelError, format, args...)
 }
@@ -1,7 +1,20 @@
 package logger
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func BenchmarkLogInfo(b *testing.B) {
@@ -17,3 +30,833 @@ func BenchmarkLogInfo(b *testing.B) {
 		LogInfo("This is an info log message %d", i)
 	}
 }
+
+// TestRotate 测试手动轮转后原有内容进入压缩备份、之后的日志写入新文件，关闭后轮转返回错误
+func TestRotate(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l := NewLogger()
+	if err := l.InitStruct(logPath); err != nil {
+		t.Fatal(err)
+	}
+	l.LogInfoStruct("before")
+	if err := l.RotateStruct(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	l.LogInfoStruct("after")
+
+	// 压缩在后台进行，原始备份被删除时压缩文件已经写完
+	var archives []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		archives, _ = filepath.Glob(logPath + ".*.tar.gz")
+		raw, _ := filepath.Glob(logPath + ".*[0-9]")
+		if len(archives) == 1 && len(raw) == 0 {
+			break
+		}
+	}
+	if len(archives) != 1 {
+		t.Fatalf("got archives %q, want 1", archives)
+	}
+	f, err := os.Open(archives[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	if _, err := tr.Next(); err != nil {
+		t.Fatal(err)
+	}
+	backup, err := io.ReadAll(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(backup)), "[INFO] before") {
+		t.Errorf("backup = %q", backup)
+	}
+
+	l.CloseStruct()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 || !strings.HasSuffix(lines[0], "[INFO] after") {
+		t.Errorf("current log = %q", data)
+	}
+	if err := l.RotateStruct(); err == nil {
+		t.Error("Rotate after Close: expected error")
+	}
+}
+
+// TestNewIndependentInstances 测试多个实例分别写入各自的文件并遵循各自的等级
+func TestNewIndependentInstances(t *testing.T) {
+	dir := t.TempDir()
+	access, err := New(Config{LogFilePath: filepath.Join(dir, "access.log")})
+	if err != nil {
+		t.Fatalf("New access: %v", err)
+	}
+	defer access.CloseStruct()
+	app, err := New(Config{LogFilePath: filepath.Join(dir, "app.log"), Level: "warn"})
+	if err != nil {
+		t.Fatalf("New app: %v", err)
+	}
+	defer app.CloseStruct()
+
+	access.LogInfoStruct("GET /")
+	app.LogInfoStruct("hidden")
+	app.LogErrorStruct("boom")
+
+	for name, want := range map[string][]string{
+		"access.log": {"[INFO] GET /"},
+		"app.log":    {"[ERROR] boom"},
+	} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		if len(lines) != len(want) {
+			t.Fatalf("%s: got %q", name, data)
+		}
+		for i, w := range want {
+			if !strings.HasSuffix(lines[i], w) {
+				t.Errorf("%s line %d: got %q, want suffix %q", name, i, lines[i], w)
+			}
+		}
+	}
+
+	if _, err := New(Config{LogFilePath: filepath.Join(dir, "x.log"), Level: "loud"}); err == nil {
+		t.Error("New with invalid level: expected error")
+	}
+}
+
+// TestCleanupBackups 测试超过数量或超过保留时间的压缩备份会被删除，名称相近的其他文件不受影响
+func TestCleanupBackups(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"app.log.20240104-000000.tar.gz":       time.Hour,
+		"app.log.20240103-12.tar.gz":           2 * time.Hour,
+		"app.log.20240102.tar.gz":              3 * time.Hour,
+		"app.log.20240101-000000.1.tar.gz":     48 * time.Hour,
+		"other.log.tar.gz":                     96 * time.Hour, // 不属于该日志文件
+		"app.log.error.tar.gz":                 96 * time.Hour, // 文件名以 app.log. 开头，但不是其备份
+		"app.log.error.20240101-000000.tar.gz": 96 * time.Hour,
+	}
+	for name, age := range ages {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cleanupBackups(logPath, retention{maxBackups: 3, maxAge: 150 * time.Minute}); err != nil {
+		t.Fatalf("cleanupBackups: %v", err)
+	}
+
+	for name, want := range map[string]bool{
+		"app.log.20240104-000000.tar.gz":       true,
+		"app.log.20240103-12.tar.gz":           true,
+		"app.log.20240102.tar.gz":              false, // 超过 MaxAge
+		"app.log.20240101-000000.1.tar.gz":     false, // 超过 MaxBackups
+		"other.log.tar.gz":                     true,
+		"app.log.error.tar.gz":                 true,
+		"app.log.error.20240101-000000.tar.gz": true,
+	} {
+		if got := fileExists(filepath.Join(dir, name)); got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestCleanupBackupsUncompressed 测试不压缩时只清理重命名后的备份，不会删除名称相近的日志文件
+func TestCleanupBackupsUncompressed(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	names := []string{"app.log", "app.log.error", "app.log.20240101-000000", "app.log.20240102-000000", "app.log.20240103-000000"}
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mtime := time.Now().Add(time.Duration(i-len(names)) * time.Hour) // 越靠后越新
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := cleanupBackups(logPath, retention{compression: CompressionNone, maxBackups: 1}); err != nil {
+		t.Fatalf("cleanupBackups: %v", err)
+	}
+
+	for i, name := range names {
+		want := i < 2 || i == len(names)-1
+		if got := fileExists(filepath.Join(dir, name)); got != want {
+			t.Errorf("%s exists = %v, want %v", name, got, want)
+		}
+	}
+}
+
+// TestArchiveBackupKeepsRawOnFailure 测试压缩失败时原始备份仍保留在磁盘上
+func TestArchiveBackupKeepsRawOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	l, err := New(Config{LogFilePath: logPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	backupPath := logPath + ".20240102-150405"
+	if err := os.WriteFile(backupPath, []byte("precious\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// 压缩目标被目录占用，compressLog 必然失败
+	if err := os.Mkdir(backupPath+".tar.gz", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	l.archiveBackup(logPath, backupPath, retention{})
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("raw backup was removed: %v", err)
+	}
+	if string(data) != "precious\n" {
+		t.Errorf("raw backup content = %q", data)
+	}
+}
+
+// TestRotateOnWrittenBytes 测试写入量超过上限时无需等待定期检查即可轮转
+func TestRotateOnWrittenBytes(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	l, err := New(Config{LogFilePath: logPath, MaxLogSizeMB: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	line := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		l.LogInfoStruct("%s", line)
+	}
+
+	// 等待后台压缩完成，避免与临时目录的清理竞争
+	var archives []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		archives, _ = filepath.Glob(logPath + ".*.tar.gz")
+		raw, _ := filepath.Glob(logPath + ".*[0-9]")
+		if len(archives) > 0 && len(raw) == 0 {
+			break
+		}
+	}
+	if len(archives) == 0 {
+		t.Fatal("expected a compressed backup after exceeding MaxLogSizeMB")
+	}
+	info, err := os.Stat(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() >= 1024*1024 {
+		t.Errorf("current log size = %d, want below 1MB after rotation", info.Size())
+	}
+}
+
+// TestSizeCheckInterval 测试按配置的间隔检查大小，其他进程写入导致超限的日志同样会被轮转
+func TestSizeCheckInterval(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, MaxLogSizeMB: 1, SizeCheckInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	// 绕过 Logger 直接追加写入，写入计数不会触发轮转
+	f, err := os.OpenFile(logPath, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.Write(make([]byte, 1<<20+1))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 压缩在后台进行，等待原始备份删除后再结束测试
+	var archives []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		archives, _ = filepath.Glob(logPath + ".*.tar.gz")
+		raw, _ := filepath.Glob(logPath + ".*[0-9]")
+		if len(archives) > 0 && len(raw) == 0 {
+			break
+		}
+	}
+	if len(archives) == 0 {
+		t.Fatal("oversized log was not rotated by the periodic check")
+	}
+}
+
+// TestFormatJSON 测试 JSON 格式输出等级字段并正确转义引号与换行
+func TestFormatJSON(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, Format: FormatJSON})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.LogWarningStruct("say \"hi\"\nbye")
+	l.CloseStruct()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry struct {
+		TS    string `json:"ts"`
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", data, err)
+	}
+	if entry.Level != "warn" || entry.Msg != "say \"hi\"\nbye" || entry.TS == "" {
+		t.Errorf("got %+v", entry)
+	}
+	if bytes.Count(data, []byte("\n")) != 1 {
+		t.Errorf("want exactly one line, got %q", data)
+	}
+}
+
+// TestLevelTokens 固定文本格式的等级前缀，与早期版本保持一致
+func TestLevelTokens(t *testing.T) {
+	for level, want := range map[int]string{
+		LevelDump:  "[DUMP] ",
+		LevelDebug: "[DEBUG] ",
+		LevelInfo:  "[INFO] ",
+		LevelWarn:  "[WARNING] ",
+		LevelError: "[ERROR] ",
+		42:         "[42] ",
+	} {
+		if got := levelToken(level); got != want {
+			t.Errorf("levelToken(%d) = %q, want %q", level, got, want)
+		}
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, Level: "dump"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.LogWarningStruct("w")
+	l.LogStruct(LevelError, "e")
+	l.CloseStruct()
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], " - [WARNING] w") || !strings.HasSuffix(lines[1], " - [ERROR] e") {
+		t.Errorf("got %q", data)
+	}
+}
+
+// TestRotateHook 测试轮转回调收到压缩备份的路径
+func TestRotateHook(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	got := make(chan string, 1)
+	l.SetRotateHookStruct(func(backupPath string) { got <- backupPath })
+	l.LogInfoStruct("before rotation")
+	if err := l.RotateStruct(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-got:
+		if !strings.HasPrefix(path, logPath+".") || !strings.HasSuffix(path, ".tar.gz") {
+			t.Errorf("hook path = %q", path)
+		}
+		if !fileExists(path) {
+			t.Errorf("archive %s does not exist", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotate hook was not called")
+	}
+}
+
+// TestCompressionNone 测试不压缩时保留重命名后的原始备份
+func TestCompressionNone(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, Compression: CompressionNone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	got := make(chan string, 1)
+	l.SetRotateHookStruct(func(backupPath string) { got <- backupPath })
+	l.LogInfoStruct("keep me")
+	if err := l.RotateStruct(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case path := <-got:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(strings.TrimSpace(string(data)), "[INFO] keep me") {
+			t.Errorf("backup content = %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("rotate hook was not called")
+	}
+
+	if _, err := New(Config{LogFilePath: logPath, Compression: "lz4"}); err == nil {
+		t.Error("New with unknown compression: expected error")
+	}
+}
+
+// TestRotatePolicy 测试按时间轮转的周期计算以及备份按周期起点命名
+func TestRotatePolicy(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	cases := []struct {
+		policy      RotatePolicy
+		start, next time.Time
+		stamp       string
+	}{
+		{RotateDaily, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), "20240102"},
+		{RotateHourly, time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 16, 0, 0, 0, time.UTC), "20240102-15"},
+	}
+	for _, c := range cases {
+		start := c.policy.periodStart(now)
+		if !start.Equal(c.start) {
+			t.Errorf("%d: periodStart = %v, want %v", c.policy, start, c.start)
+		}
+		if next := c.policy.nextPeriod(start); !next.Equal(c.next) {
+			t.Errorf("%d: nextPeriod = %v, want %v", c.policy, next, c.next)
+		}
+		if stamp := start.Format(c.policy.stampLayout()); stamp != c.stamp {
+			t.Errorf("%d: stamp = %q, want %q", c.policy, stamp, c.stamp)
+		}
+	}
+	if got := RotateSize.periodStart(now); !got.Equal(now) {
+		t.Errorf("RotateSize: periodStart = %v, want %v", got, now)
+	}
+
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, Rotation: RotateDaily})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+	l.LogInfoStruct("yesterday")
+	if err := l.rotateLogFileAs(logPath, cases[0].stamp); err != nil {
+		t.Fatal(err)
+	}
+	archive := logPath + ".20240102.tar.gz"
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(logPath + ".20240102"); os.IsNotExist(err) {
+			break // 原始备份在压缩完成后删除
+		}
+	}
+	if _, err := os.Stat(archive); err != nil {
+		t.Fatalf("period backup not created: %v", err)
+	}
+}
+
+// TestInitWithConfigTwice 测试已初始化时再次 InitWithConfig 返回错误，且不影响正在进行的写入与原有配置
+func TestInitWithConfigTwice(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	l, err := New(Config{LogFilePath: logPath, Level: "info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				l.LogInfoStruct("busy")
+			}
+		}
+	}()
+	for i := 0; i < 10; i++ {
+		err := l.InitWithConfigStruct(Config{LogFilePath: filepath.Join(dir, "other.log"), MaxLogSizeMB: 1, Level: "error"})
+		if err == nil {
+			t.Fatal("second InitWithConfig: expected error")
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if l.logLevel.Load().(int) != LevelInfo || l.maxLogSizeMB.Load() != 100 || l.logFilePath != logPath {
+		t.Errorf("configuration changed by a rejected InitWithConfig")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.log")); err == nil {
+		t.Error("rejected InitWithConfig opened a new file")
+	}
+
+	// Close 之后可以使用新的配置重新初始化
+	l.CloseStruct()
+	if err := l.InitWithConfigStruct(Config{LogFilePath: filepath.Join(dir, "other.log"), Format: FormatJSON}); err != nil {
+		t.Fatalf("InitWithConfig after Close: %v", err)
+	}
+	if l.format != FormatJSON {
+		t.Error("new configuration not applied after Close")
+	}
+}
+
+// TestReinitWhileLogging 测试其他协程持续记录日志时反复 Close 与 InitWithConfig，需配合 -race 运行
+func TestReinitWhileLogging(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(Config{LogFilePath: filepath.Join(dir, "app.log")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.CloseStruct()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					l.LogErrorStruct("busy")
+					l.LogCtxStruct(WithRequestID(context.Background(), "req"), LevelInfo, "busy")
+				}
+			}
+		}()
+	}
+	for i, deadline := 0, time.Now().Add(200*time.Millisecond); time.Now().Before(deadline); i++ {
+		l.CloseStruct()
+		cfg := Config{LogFilePath: filepath.Join(dir, "app.log"), MaxLogSizeMB: 1 + i%2}
+		if i%2 == 1 {
+			cfg.Format = FormatJSON
+			cfg.Caller = true
+			cfg.Rotation = RotateHourly
+			cfg.ErrorLogFilePath = filepath.Join(dir, "error.log")
+		}
+		if err := l.InitWithConfigStruct(cfg); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+// TestInitWithConfigInvalid 测试配置无效或文件无法打开时 InitWithConfig 不修改任何配置
+func TestInitWithConfigInvalid(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	l, err := New(Config{LogFilePath: logPath, Level: "info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.CloseStruct()
+
+	for _, cfg := range []Config{
+		{LogFilePath: logPath, Level: "warn", Format: FormatJSON, Caller: true, ErrorLogFilePath: filepath.Join(dir, "error.log"), ErrorLevel: "loud"},
+		{LogFilePath: logPath, Level: "warn", Format: FormatJSON, Compression: "lz4"},
+		{LogFilePath: logPath, Level: "warn", Format: FormatJSON, ErrorLogFilePath: filepath.Join(dir, "missing", "error.log")},
+	} {
+		if err := l.InitWithConfigStruct(cfg); err == nil {
+			t.Fatalf("InitWithConfig(%+v): expected error", cfg)
+		}
+		if l.format != FormatText || l.caller || l.compression != "" || l.errLogger != nil || l.GetLogLevelStruct() != "info" {
+			t.Errorf("configuration changed by a failed InitWithConfig(%+v)", cfg)
+		}
+		if l.logFile != nil {
+			t.Errorf("failed InitWithConfig(%+v) left the log file open", cfg)
+		}
+	}
+	if fileExists(filepath.Join(dir, "error.log")) {
+		t.Error("invalid configuration opened the error log file")
+	}
+}
+
+// TestReinitAfterClose 测试 Close 之后可以再次 Init，且关闭期间的日志被丢弃
+func TestReinitAfterClose(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLogger()
+	l.LogInfoStruct("before init") // 未初始化时不应 panic
+
+	if err := l.InitStruct(filepath.Join(dir, "first.log")); err != nil {
+		t.Fatal(err)
+	}
+	l.LogInfoStruct("first")
+	l.CloseStruct()
+	l.LogInfoStruct("while closed")
+
+	if err := l.InitStruct(filepath.Join(dir, "second.log")); err != nil {
+		t.Fatalf("re-init: %v", err)
+	}
+	l.LogInfoStruct("second")
+	l.CloseStruct()
+
+	if got := l.ResetDroppedLogsStruct(); got != 2 {
+		t.Errorf("dropped = %d, want 2", got)
+	}
+	if got := l.DumpDroppedLogsStruct(); got != 0 {
+		t.Errorf("dropped after reset = %d, want 0", got)
+	}
+
+	for name, want := range map[string]string{"first.log": "first", "second.log": "second"} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 1 || !strings.HasSuffix(lines[0], "[INFO] "+want) {
+			t.Errorf("%s = %q, want a single %q entry", name, data, want)
+		}
+	}
+}
+
+// TestCallerInfo 测试各入口记录的调用位置均为调用方所在行
+func TestCallerInfo(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath, Caller: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	mark := func() {
+		_, _, line, _ := runtime.Caller(1)
+		want = append(want, fmt.Sprintf("logger_test.go:%d: ", line+1))
+	}
+	mark()
+	l.LogStruct(LevelInfo, "a")
+	mark()
+	l.LogfStruct(LevelInfo, "%s", "b")
+	mark()
+	l.LogWarningStruct("c")
+	l.CloseStruct()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got %q", data)
+	}
+	for i, w := range want {
+		if !strings.Contains(lines[i], w) {
+			t.Errorf("line %d = %q, want caller %q", i, lines[i], w)
+		}
+	}
+}
+
+// TestErrorLogFile 测试高等级日志同时写入主日志与错误日志
+func TestErrorLogFile(t *testing.T) {
+	dir := t.TempDir()
+	l, err := New(Config{
+		LogFilePath:      filepath.Join(dir, "app.log"),
+		ErrorLogFilePath: filepath.Join(dir, "error.log"),
+		ErrorLevel:       "warn",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.LogInfoStruct("info")
+	l.LogWarningStruct("warn")
+	l.LogErrorStruct("error")
+	if err := l.FlushStruct(); err != nil {
+		t.Fatal(err)
+	}
+	l.CloseStruct()
+
+	for name, want := range map[string]int{"app.log": 3, "error.log": 2} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bytes.Count(data, []byte("\n")); got != want {
+			t.Errorf("%s has %d lines, want %d: %q", name, got, want, data)
+		}
+	}
+}
+
+// TestErrorLogFileReinit 测试重新初始化时旧的错误日志实例被关闭，新配置生效，不再配置时不再写入
+func TestErrorLogFileReinit(t *testing.T) {
+	dir := t.TempDir()
+	cfg := Config{
+		LogFilePath:      filepath.Join(dir, "app.log"),
+		ErrorLogFilePath: filepath.Join(dir, "error1.log"),
+	}
+	l, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := l.errLogger
+	l.CloseStruct()
+
+	cfg.ErrorLogFilePath = filepath.Join(dir, "error2.log")
+	if err := l.InitWithConfigStruct(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if l.errLogger == old {
+		t.Fatal("errLogger was not replaced")
+	}
+	old.logFileMutex.Lock()
+	leaked := old.logFile != nil
+	old.logFileMutex.Unlock()
+	if leaked {
+		t.Error("old errLogger still holds its file open")
+	}
+	l.LogErrorStruct("second")
+	l.CloseStruct()
+
+	cfg.ErrorLogFilePath = ""
+	if err := l.InitWithConfigStruct(cfg); err != nil {
+		t.Fatal(err)
+	}
+	if l.errLogger != nil {
+		t.Error("errLogger kept after reinit without ErrorLogFilePath")
+	}
+	l.LogErrorStruct("third")
+	l.CloseStruct()
+
+	for name, want := range map[string]int{"error1.log": 0, "error2.log": 1, "app.log": 2} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bytes.Count(data, []byte("\n")); got != want {
+			t.Errorf("%s has %d lines, want %d: %q", name, got, want, data)
+		}
+	}
+}
+
+// TestErrorLogFileRetention 测试主日志与错误日志各自按 MaxBackups 保留备份，不会删除对方的备份或正在写入的错误日志
+func TestErrorLogFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "app.log")
+	errPath := logPath + ".error" // 与主日志的备份共用前缀
+	for _, compression := range []Compression{CompressionNone, CompressionGzip} {
+		l, err := New(Config{LogFilePath: logPath, ErrorLogFilePath: errPath, MaxBackups: 2, Compression: compression})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			l.LogErrorStruct("entry %d", i)
+			if err := l.errLogger.RotateStruct(); err != nil {
+				t.Fatal(err)
+			}
+			if err := l.RotateStruct(); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// 压缩与清理在后台进行，等待原始备份都已压缩且两者都只剩 MaxBackups 个备份
+		count := func(path, ext string) int {
+			matches, _ := filepath.Glob(path + ".*")
+			n := 0
+			for _, m := range matches {
+				if isBackupOf(m, path, ext) {
+					n++
+				}
+			}
+			return n
+		}
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			raw := 0
+			if compression != CompressionNone {
+				raw = count(logPath, "") + count(errPath, "")
+			}
+			if raw == 0 && count(logPath, compression.ext()) == 2 && count(errPath, compression.ext()) == 2 {
+				break
+			}
+		}
+		if got := count(logPath, compression.ext()); got != 2 {
+			t.Errorf("%s: app.log has %d backups, want 2", compression, got)
+		}
+		if got := count(errPath, compression.ext()); got != 2 {
+			t.Errorf("%s: error log has %d backups, want 2", compression, got)
+		}
+		l.LogErrorStruct("after rotation")
+		l.CloseStruct()
+		data, err := os.ReadFile(errPath)
+		if err != nil {
+			t.Fatalf("%s: live error log removed: %v", compression, err)
+		}
+		if !strings.HasSuffix(strings.TrimSpace(string(data)), "[ERROR] after rotation") {
+			t.Errorf("%s: error log = %q", compression, data)
+		}
+
+		// 下一轮从空目录开始
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			os.Remove(filepath.Join(dir, e.Name()))
+		}
+	}
+}
+
+// TestGetLogLevel 测试 GetLogLevel 返回与 SetLogLevel 一致的小写名称
+func TestGetLogLevel(t *testing.T) {
+	l := NewLogger()
+	if got := l.GetLogLevelStruct(); got != "dump" {
+		t.Errorf("default level = %q, want %q", got, "dump")
+	}
+	for _, level := range []string{"debug", "INFO", "warn", "error", "none"} {
+		if err := l.SetLogLevelStruct(level); err != nil {
+			t.Fatal(err)
+		}
+		if got := l.GetLogLevelStruct(); got != strings.ToLower(level) {
+			t.Errorf("after SetLogLevel(%q): GetLogLevel = %q", level, got)
+		}
+	}
+	if err := l.SetLogLevelStruct("loud"); err == nil {
+		t.Error("SetLogLevel(\"loud\"): expected error")
+	}
+	if got := l.GetLogLevelStruct(); got != "none" {
+		t.Errorf("invalid SetLogLevel changed the level to %q", got)
+	}
+}
+
+// TestLogCtx 测试请求 ID 输出在消息之前
+func TestLogCtx(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "app.log")
+	l, err := New(Config{LogFilePath: logPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.LogCtxStruct(WithRequestID(context.Background(), "req-1"), LevelInfo, "handled")
+	l.LogCtxStruct(context.Background(), LevelInfo, "no id")
+	l.CloseStruct()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], "[INFO] [req-1] handled") || !strings.HasSuffix(lines[1], "[INFO] no id") {
+		t.Errorf("got %q", data)
+	}
+}